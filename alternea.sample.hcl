@@ -46,7 +46,7 @@ server "main" {
       // tablifier transforms incoming data into a tabular form ([][]string)
       // required by the "csv" transformer
       tablifier = {
-        name = "json" // the only available tablifier for now is json
+        name = "json" // available tablifiers are described below
 
         // before passing the data to the tablifier, they can be preprocessed
         // by optionally defining "remapper"
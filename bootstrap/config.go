@@ -12,6 +12,17 @@ import (
 
 type Config map[string]any
 
+// pathParametersKey holds the names of the route path parameters, the proxy routes initializer sets it
+// to the configuration of the transformers and extractConfigIfSet passes it on to the nested configurations,
+// the "$" prefix keeps it apart from the configuration attributes
+const pathParametersKey = "$path_parameters"
+
+// pathParameters returns the names of the route path parameters which the configuration belongs to
+func (c Config) pathParameters() []string {
+	names, _ := c[pathParametersKey].([]string)
+	return names
+}
+
 func (c Config) GetString(name string, defaultVal ...string) string {
 	if v, ok := c[name]; ok {
 		if str, ok := v.(string); ok {
@@ -28,19 +39,26 @@ func extractConfigIfSet(name string, config Config) (Config, bool) {
 	if subconfig, ok := config[name]; ok {
 		switch result := subconfig.(type) {
 		case Config:
-			return result, true
+			return inheritPathParameters(result, config), true
 		case map[string]any:
-			return result, true
+			return inheritPathParameters(result, config), true
 		case string:
 			conf := make(Config)
 			if err := json.Unmarshal([]byte(result), &conf); err == nil {
-				return conf, true
+				return inheritPathParameters(conf, config), true
 			}
 		}
 	}
 	return config, false
 }
 
+func inheritPathParameters(config, parent Config) Config {
+	if names, ok := parent[pathParametersKey]; ok && config != nil {
+		config[pathParametersKey] = names
+	}
+	return config
+}
+
 func decode(in, out any) error {
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		ErrorUnused: false,
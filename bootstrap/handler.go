@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 
 	"github.com/velmie/alternea/httpbackend"
 	"github.com/velmie/alternea/manipulation"
@@ -15,21 +16,59 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	contentTypeHeader        = "Content-Type"
+	contentDispositionHeader = "Content-Disposition"
+)
+
 type HTTPRoutesInitializer interface {
 	InitRoutes(router route.Router, config *ServerConfig) error
 }
 
 type HTTPRoutesInitializers []HTTPRoutesInitializer
 
+// InitRoutes initializes the routes of the server, then the routes of the route groups
 func (h HTTPRoutesInitializers) InitRoutes(router route.Router, config *ServerConfig) error {
 	for _, initializer := range h {
 		if err := initializer.InitRoutes(router, config); err != nil {
 			return err
 		}
 	}
+	for _, group := range config.RouteGroups {
+		groupRouter, err := conditionalRouter(router, &group.Match)
+		if err != nil {
+			return errors.Wrapf(err, "cannot create route group '%s'", group.Name)
+		}
+		groupConfig := &ServerConfig{
+			Name:           config.Name,
+			ProxyServices:  group.ProxyServices,
+			StaticServices: group.StaticServices,
+		}
+		for _, initializer := range h {
+			if err := initializer.InitRoutes(groupRouter, groupConfig); err != nil {
+				return errors.Wrapf(err, "route group '%s'", group.Name)
+			}
+		}
+	}
 	return nil
 }
 
+// conditionalRouter returns the router of the routes which handle the requests matching the conditions,
+// the router must support the route groups e.g. route.ConditionalRouter
+func conditionalRouter(router route.Router, cfg *MatchConfig) (route.Router, error) {
+	groupRouter, ok := router.(interface {
+		Group(conditions route.Conditions) (route.Router, error)
+	})
+	if !ok {
+		return nil, errors.New("the router does not support match conditions, nested match blocks are not allowed")
+	}
+	return groupRouter.Group(route.Conditions{
+		Host:    cfg.Host,
+		Headers: cfg.Headers,
+		Query:   cfg.Query,
+	})
+}
+
 type ProxyRoutesInitializer struct {
 	backendErrorHandler func(err error) (proceed bool)
 	errorHandler        func(err error, w http.ResponseWriter, r *http.Request)
@@ -62,14 +101,20 @@ func (p *ProxyRoutesInitializer) setHandler(
 	if srv.Method != "" {
 		method = srv.Method
 	}
+	if srv.Match != nil {
+		var err error
+		if router, err = conditionalRouter(router, srv.Match); err != nil {
+			return errors.Wrap(err, "ProxyRoutesInitializer: cannot apply match conditions")
+		}
+	}
 	targetURL, err := url.Parse(srv.Backend.TargetURL)
 	if err != nil {
 		return errors.Wrap(err, "ProxyRoutesInitializer: cannot parse target url")
 	}
 
-	transformer, err := p.createTransformer(&srv.Transformer)
+	pathParameters, err := route.PathParameterNames(srv.PathTemplate)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "ProxyRoutesInitializer: cannot parse path template")
 	}
 
 	requestIterator := p.requestIterator()
@@ -88,48 +133,313 @@ func (p *ProxyRoutesInitializer) setHandler(
 	if len(srv.Backend.SuccessHTTPStatusCodes) > 0 {
 		handlerConfig.SuccessHTTPStatusCodes = srv.Backend.SuccessHTTPStatusCodes
 	}
-	transformerHandler := service.NewTransformerHandler(
-		transformer,
-		requestIterator,
-		proxyBackend,
-		handlerConfig,
+	if srv.ResponseHeaders != nil {
+		handlerConfig.PassHeaders = srv.ResponseHeaders.Pass
+		handlerConfig.PreferBackendHeaders = srv.ResponseHeaders.PreferBackend
+	}
+	if srv.Validator != nil {
+		validator, err := manipulation.NewJSONSchemaValidator(srv.Validator.Schema)
+		if err != nil {
+			return errors.Wrap(err, "ProxyRoutesInitializer: cannot create validator")
+		}
+		handlerConfig.Validator = validator
+	}
+	variants, err := p.createVariants(srv, pathParameters, requestIterator, proxyBackend, handlerConfig)
+	if err != nil {
+		return err
+	}
+	negotiation := make([]service.Variant, len(variants))
+	for i, v := range variants {
+		negotiation[i] = v.Variant
+	}
+
+	var (
+		requestTransformer *manipulation.RequestBodyTransformer
+		maxRequestBodySize int64
 	)
-	transformerHandler.SetFlushInterval(srv.FlushInterval)
+	if srv.RequestTransformer != nil {
+		requestTransformer, maxRequestBodySize, err = p.createRequestTransformer(srv.RequestTransformer, pathParameters)
+		if err != nil {
+			return errors.Wrap(err, "ProxyRoutesInitializer: cannot create request transformer")
+		}
+	}
+
+	var requestHeaders *service.RequestHeaderRules
+	if srv.RequestHeaders != nil {
+		requestHeaders, err = service.NewRequestHeaderRules(service.RequestHeaderRulesConfig{
+			Allow:     srv.RequestHeaders.Allow,
+			Deny:      srv.RequestHeaders.Deny,
+			Set:       srv.RequestHeaders.Set,
+			Append:    srv.RequestHeaders.Append,
+			Forwarded: srv.RequestHeaders.Forwarded,
+		})
+		if err != nil {
+			return errors.Wrap(err, "ProxyRoutesInitializer: cannot create request headers rules")
+		}
+	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		for name, value := range srv.SetHeader {
+		variant := variants[0]
+		if len(variants) > 1 {
+			w.Header().Add("Vary", "Accept")
+			request := manipulation.RequestFromContext(r.Context())
+			i, err := service.Negotiate(
+				negotiation,
+				request.Query[service.FormatQueryParameter],
+				request.Headers["Accept"],
+			)
+			if err != nil {
+				p.handleError(err, w, r)
+				return
+			}
+			variant = variants[i]
+		}
+		if requestTransformer != nil {
+			if err := service.TransformRequestBody(r, requestTransformer, maxRequestBodySize); err != nil {
+				p.handleError(err, w, r)
+				return
+			}
+		}
+		if requestHeaders != nil {
+			if err := requestHeaders.Apply(r); err != nil {
+				p.handleError(err, w, r)
+				return
+			}
+		}
+		for name, value := range variant.headers {
 			w.Header().Set(name, value)
 		}
-		err = transformerHandler.Handle(r.Context(), w, r)
+		if variant.filename != nil {
+			disposition, err := variant.filename.ContentDisposition(r.Context())
+			if err != nil {
+				p.handleError(err, w, r)
+				return
+			}
+			w.Header().Set(contentDispositionHeader, disposition)
+		}
+		err := variant.handler.Handle(r.Context(), w, r)
 
 		if err != nil {
-			for name := range srv.SetHeader {
+			for name := range variant.headers {
 				w.Header().Del(name)
 			}
-			if p.errorHandler != nil {
-				p.errorHandler(err, w, r)
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-			}
+			w.Header().Del(contentDispositionHeader)
+			p.handleError(err, w, r)
 			return
 		}
 	})
 
-	err = router.Handle(method, srv.PathTemplate, route.PathSubstitution(targetPathTemplate, handler))
+	var next http.Handler = handler
+	if srv.Query != nil {
+		next = route.QueryRewrite(queryRules(srv.Query), next)
+	}
+	if len(variants) > 1 {
+		next = negotiationStripHandler(next)
+	}
+	// the request data is captured before the query string is rewritten,
+	// so that remappers and templates get the parameters sent by the client
+	next = requestDataHandler(next)
+	next = headAsGetHandler(next)
+
+	err = router.Handle(method, srv.PathTemplate, route.PathSubstitution(targetPathTemplate, next))
 	if err != nil {
 		return errors.Wrap(err, "ProxyRoutesInitializer: cannot create new route")
 	}
 	return nil
 }
 
-func (p *ProxyRoutesInitializer) createTransformer(cfg *DynamicConfig) (manipulation.DataTransformer, error) {
+// variant is the output format of the proxy service
+type variant struct {
+	service.Variant
+	handler  *service.TransformerHandler
+	headers  map[string]string
+	filename *service.FilenameTemplate
+}
+
+// createVariants creates a variant per transformer, the format of a variant is set by the "format" attribute
+// of the transformer and defaults to the transformer name
+func (p *ProxyRoutesInitializer) createVariants(
+	srv *ProxyServiceConfig,
+	pathParameters []string,
+	requestIterator service.RequestIterator,
+	backend httpbackend.RequestHandler,
+	handlerConfig *service.TransformerHandlerConfig,
+) ([]*variant, error) {
+	if len(srv.Transformers) == 0 {
+		return nil, errors.Errorf("ProxyRoutesInitializer: %s block is required", TransformerReferenceName)
+	}
+	variants := make([]*variant, 0, len(srv.Transformers))
+	formats := make(map[string]bool, len(srv.Transformers))
+	for _, cfg := range srv.Transformers {
+		transformer, err := p.createTransformer(cfg, pathParameters)
+		if err != nil {
+			return nil, err
+		}
+		config, err := cfg.ToConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "ProxyRoutesInitializer: cannot get transformer config")
+		}
+		v := &variant{Variant: service.Variant{Format: config.GetString("format", cfg.Name)}}
+		if formats[strings.ToLower(v.Format)] {
+			return nil, errors.Errorf(
+				"ProxyRoutesInitializer: format '%s' is used by several transformers, set unique \"format\" attributes",
+				v.Format,
+			)
+		}
+		formats[strings.ToLower(v.Format)] = true
+		if provider, ok := transformer.(manipulation.MediaTypeProvider); ok {
+			v.MediaType = provider.MediaType()
+		}
+		if v.headers, v.filename, err = p.responseHeaders(srv, transformer); err != nil {
+			return nil, err
+		}
+		v.handler = service.NewTransformerHandler(transformer, requestIterator, backend, handlerConfig)
+		v.handler.SetFlushInterval(srv.FlushInterval)
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+// responseHeaders returns the headers to set in the response: the set_header ones and Content-Type
+// of the transformer unless it is set, and the template of the Content-Disposition file name if configured
+func (p *ProxyRoutesInitializer) responseHeaders(
+	srv *ProxyServiceConfig,
+	transformer manipulation.DataTransformer,
+) (map[string]string, *service.FilenameTemplate, error) {
+	var mediaType, extension string
+	if provider, ok := transformer.(manipulation.MediaTypeProvider); ok {
+		mediaType, extension = provider.MediaType(), provider.FileExtension()
+	}
+	headers := make(map[string]string, len(srv.SetHeader)+1)
+	for name, value := range srv.SetHeader {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	if _, ok := headers[contentTypeHeader]; !ok && mediaType != "" {
+		headers[contentTypeHeader] = mediaType
+	}
+	if srv.Filename == "" {
+		return headers, nil, nil
+	}
+	if _, ok := headers[contentDispositionHeader]; ok {
+		return nil, nil, errors.Errorf(
+			"ProxyRoutesInitializer: filename and set_header %s cannot be used together",
+			contentDispositionHeader,
+		)
+	}
+	filename, err := service.NewFilenameTemplate(srv.Filename, extension)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "ProxyRoutesInitializer: cannot create filename template")
+	}
+	return headers, filename, nil
+}
+
+func (p *ProxyRoutesInitializer) handleError(err error, w http.ResponseWriter, r *http.Request) {
+	if p.errorHandler != nil {
+		p.errorHandler(err, w, r)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func queryRules(cfg *QueryConfig) *route.QueryRules {
+	rules := &route.QueryRules{
+		Allow:  cfg.Allow,
+		Drop:   cfg.Drop,
+		Rename: cfg.Rename,
+		Set:    make(map[string]route.PathTemplate, len(cfg.Set)),
+	}
+	for name, value := range cfg.Set {
+		rules.Set[name] = route.ColonParamsReplaceTemplate(value)
+	}
+	return rules
+}
+
+// negotiationStripHandler removes the format query parameter and the Accept header, which select the variant
+// of the response, from the request so that they are not passed to the backend,
+// the variant is negotiated by means of the request data captured before
+func negotiationStripHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if _, ok := query[service.FormatQueryParameter]; ok {
+			query.Del(service.FormatQueryParameter)
+			r.URL.RawQuery = query.Encode()
+		}
+		r.Header.Del("Accept")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// headAsGetHandler requests the backend by GET when the client sends HEAD since the transformers need
+// the response body, the server discards the body of the response to HEAD
+func headAsGetHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			// the server checks the method of the original request to discard the body, so it is not modified
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestDataHandler makes the request data available to remappers and templates, see manipulation.RequestData
+func requestDataHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(manipulation.ContextWithRequest(r.Context(), r)))
+	})
+}
+
+// createTransformer creates the transformer of the route, the remappers are given the names
+// of the route path parameters
+func (p *ProxyRoutesInitializer) createTransformer(
+	cfg *DynamicConfig,
+	pathParameters []string,
+) (manipulation.DataTransformer, error) {
 	transformerCfg, err := cfg.ToConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "ProxyRoutesInitializer: cannot get transformer config")
 	}
+	transformerCfg[pathParametersKey] = pathParameters
 	return Transformer.Create(cfg.Name, transformerCfg)
 }
 
+// createRequestTransformer creates the request body transformer and returns the maximum body size
+func (p *ProxyRoutesInitializer) createRequestTransformer(
+	cfg *RequestTransformerConfig,
+	pathParameters []string,
+) (*manipulation.RequestBodyTransformer, int64, error) {
+	const entryName = RequestTransformerReferenceName
+
+	config, err := cfg.ToConfig()
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: cannot get config", entryName)
+	}
+	config[pathParametersKey] = pathParameters
+	var limits struct {
+		MaxBodySize int64
+	}
+	if err = decode(config, &limits); err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+	}
+
+	var remapper manipulation.Remapper
+	if remapperConfig, exist := extractConfigIfSet(RemapperReferenceName, config); exist {
+		remapper, err = Remapper.Create(remapperConfig.GetString("name"), remapperConfig)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "%s: cannot create remapper", entryName)
+		}
+	}
+	var tablifier manipulation.Tablifier
+	if tablifierConfig, exist := extractConfigIfSet(TablifierReferenceName, config); exist {
+		tablifierFactory := ProcessingTablifierFactory(Tablifier)
+		tablifier, err = tablifierFactory.Create(tablifierConfig.GetString("name"), tablifierConfig)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "%s: cannot create %s", entryName, TablifierReferenceName)
+		}
+	}
+	return manipulation.NewRequestBodyTransformer(remapper, tablifier), limits.MaxBodySize, nil
+}
+
 func (p *ProxyRoutesInitializer) requestIterator() service.RequestIterator {
 	return service.NewDirectRequestIterator()
 }
@@ -179,6 +489,12 @@ func (s *StaticContentRoutesInitializer) setHandler(
 	if srv.Method != "" {
 		method = srv.Method
 	}
+	if srv.Match != nil {
+		var err error
+		if router, err = conditionalRouter(router, srv.Match); err != nil {
+			return errors.Wrap(err, "StaticContentRoutesInitializer: cannot apply match conditions")
+		}
+	}
 	var content []byte
 	if srv.Content != "" {
 		content = []byte(srv.Content)
@@ -0,0 +1,293 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclsimple"
+
+	"github.com/velmie/alternea/route"
+)
+
+// echoBackend responds with the method, the path, the query string and the Accept header of the request,
+// the method is also sent as the X-Method header
+func echoBackend(t *testing.T) *httptest.Server {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Method", r.Method)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+			"accept": r.Header.Get("Accept"),
+		})
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+// testServer initializes the routes of the server defined by the configuration
+// in the same way as the application does
+func testServer(t *testing.T, config string) http.Handler {
+	server, err := initTestServer(t, config)
+	if err != nil {
+		t.Fatalf("cannot initialize routes: %s", err)
+	}
+	return server
+}
+
+func initTestServer(t *testing.T, config string) (http.Handler, error) {
+	cfg := &RootConfig{}
+	if err := hclsimple.Decode("test.hcl", []byte(config), evalContext, cfg); err != nil {
+		t.Fatalf("cannot decode configuration: %s", err)
+	}
+	router := route.NewConditionalRouter(route.NewTreeRouter())
+	initializers := HTTPRoutesInitializers{
+		NewProxyRoutesInitializer(DefaultBackendErrorHandler(t.Log), DefaultErrorHandler(t.Log)),
+		NewStaticContentRoutesInitializer(),
+	}
+	if err := initializers.InitRoutes(router, cfg.Servers[0]); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+func TestProxyServiceNegotiationIsNotForwarded(t *testing.T) {
+	backend := echoBackend(t)
+	server := testServer(t, fmt.Sprintf(`
+server "main" {
+  listen = ":0"
+  proxy_service "/negotiated" {
+    backend {
+      target_url = "%[1]s/items"
+    }
+    transformer "json" {}
+    transformer "json" {
+      format = "raw"
+    }
+  }
+  proxy_service "/single" {
+    backend {
+      target_url = "%[1]s/items"
+    }
+    transformer "json" {}
+  }
+}`, backend.URL))
+
+	tests := []struct {
+		target   string
+		accept   string
+		expected map[string]string
+	}{
+		{
+			target:   "/negotiated?format=raw&page=2",
+			accept:   "application/json",
+			expected: map[string]string{"method": "GET", "path": "/items", "query": "page=2", "accept": ""},
+		},
+		{
+			target:   "/single?format=raw&page=2",
+			accept:   "application/json",
+			expected: map[string]string{"method": "GET", "path": "/items", "query": "format=raw&page=2", "accept": "application/json"},
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: GET %s,", i, tt.target)
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s expected status %d, got %d %s", meta, http.StatusOK, w.Code, w.Body)
+			continue
+		}
+		var actual map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &actual); err != nil {
+			t.Errorf("%s cannot decode response %s: %s", meta, w.Body, err)
+			continue
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s expected backend request %v, got %v", meta, tt.expected, actual)
+		}
+	}
+}
+
+func TestProxyServiceHead(t *testing.T) {
+	backend := echoBackend(t)
+	server := httptest.NewServer(testServer(t, fmt.Sprintf(`
+server "main" {
+  listen = ":0"
+  proxy_service "/items" {
+    backend {
+      target_url = "%s/items"
+    }
+    response_headers {
+      pass = ["X-Method"]
+    }
+    transformer "json" {}
+  }
+}`, backend.URL)))
+	defer server.Close()
+
+	response, err := http.Head(server.URL + "/items")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, response.StatusCode)
+	}
+	if method := response.Header.Get("X-Method"); method != http.MethodGet {
+		t.Errorf("expected the backend to be requested by GET, got %q", method)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", contentType)
+	}
+	if len(body) != 0 {
+		t.Errorf("expected no body, got %s", body)
+	}
+}
+
+func TestRouteGroups(t *testing.T) {
+	backend := echoBackend(t)
+	server := testServer(t, fmt.Sprintf(`
+server "main" {
+  listen = ":0"
+  static_service "/status" {
+    response_code = 200
+    content       = "default"
+  }
+  static_service "/status" {
+    match {
+      headers = { X-Debug = "" }
+    }
+    response_code = 200
+    content       = "debug"
+  }
+  static_service "/ping" {
+    method = "POST"
+    match {
+      headers = { x-debug = "" }
+    }
+    response_code = 200
+    content       = "debug pong"
+  }
+  route_group "tenants" {
+    match {
+      host = ":tenantID.example.com"
+    }
+    proxy_service "/exports/:id" {
+      backend {
+        target_url = "%s/tenants/:tenantID/exports/:id"
+      }
+      transformer "json" {}
+    }
+    static_service "/status" {
+      response_code = 200
+      content       = "tenant"
+    }
+  }
+}`, backend.URL))
+
+	tests := []struct {
+		method   string
+		host     string
+		target   string
+		debug    bool
+		code     int
+		expected string
+	}{
+		{http.MethodGet, "example.com", "/status", false, http.StatusOK, "default"},
+		{http.MethodGet, "example.com", "/status", true, http.StatusOK, "debug"},
+		{http.MethodGet, "acme.example.com", "/status", false, http.StatusOK, "tenant"},
+		{http.MethodGet, "acme.example.com", "/status", true, http.StatusOK, "debug"},
+		{http.MethodPost, "example.com", "/ping", true, http.StatusOK, "debug pong"},
+		{http.MethodPost, "example.com", "/ping", false, http.StatusNotFound, ""},
+		{http.MethodGet, "example.com", "/ping", true, http.StatusMethodNotAllowed, ""},
+		{http.MethodPost, "example.com", "/status", true, http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "example.com", "/exports/7", false, http.StatusNotFound, ""},
+		{
+			http.MethodGet, "Acme.example.com", "/exports/7", false, http.StatusOK,
+			`{"accept":"","method":"GET","path":"/tenants/acme/exports/7","query":""}`,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %s %s%s, debug %t,", i, tt.method, tt.host, tt.target, tt.debug)
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		r.Host = tt.host
+		if tt.debug {
+			r.Header.Set("X-Debug", "1")
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s expected status %d, got %d", meta, tt.code, w.Code)
+			continue
+		}
+		if tt.code == http.StatusOK && strings.TrimSpace(w.Body.String()) != tt.expected {
+			t.Errorf("%s expected %s, got %s", meta, tt.expected, w.Body)
+		}
+	}
+
+	// the services with the same conditions share the group, so their routes conflict as usual
+	_, err := initTestServer(t, `
+server "main" {
+  listen = ":0"
+  static_service "/ping" {
+    match {
+      headers = { X-Debug = "" }
+    }
+    response_code = 200
+  }
+  static_service "/ping" {
+    match {
+      headers = { x-debug = "" }
+    }
+    response_code = 204
+  }
+}`)
+	if err == nil {
+		t.Errorf("expected to return error for the services with the same path and conditions")
+	}
+}
+
+func TestProxyServiceJQPathParameters(t *testing.T) {
+	backend := echoBackend(t)
+	config := `
+server "main" {
+  listen = ":0"
+  proxy_service "/accounts/:id" {
+    backend {
+      target_url = "%s/accounts/:id"
+    }
+    transformer "json" {
+      remapper = {
+        name  = "chain"
+        steps = [
+          {
+            name    = "jq"
+            program = "{path, id: %s}"
+          },
+        ]
+      }
+    }
+  }
+}`
+	server := testServer(t, fmt.Sprintf(config, backend.URL, "$id"))
+	r := httptest.NewRequest(http.MethodGet, "/accounts/42", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	expected := `{"id":"42","path":"/accounts/42"}`
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != expected {
+		t.Errorf("expected %d %s, got %d %s", http.StatusOK, expected, w.Code, w.Body)
+	}
+
+	if _, err := initTestServer(t, fmt.Sprintf(config, backend.URL, "$acount")); err == nil {
+		t.Errorf("expected to return error for the variable which is not a path parameter")
+	}
+}
@@ -47,25 +47,71 @@ type ServerConfig struct {
 	IdleTimeout    time.Duration          `hcl:"idle_timeout,optional"`
 	ProxyServices  []*ProxyServiceConfig  `hcl:"proxy_service,block"`
 	StaticServices []*StaticServiceConfig `hcl:"static_service,block"`
+	RouteGroups    []*RouteGroupConfig    `hcl:"route_group,block"`
+}
+
+type RouteGroupConfig struct {
+	Name           string                 `hcl:"name,label"`
+	Match          MatchConfig            `hcl:"match,block"`
+	ProxyServices  []*ProxyServiceConfig  `hcl:"proxy_service,block"`
+	StaticServices []*StaticServiceConfig `hcl:"static_service,block"`
+}
+
+type MatchConfig struct {
+	Host    string            `hcl:"host,optional"`
+	Headers map[string]string `hcl:"headers,optional"`
+	Query   map[string]string `hcl:"query,optional"`
 }
 
 type ProxyServiceConfig struct {
-	Method        string            `hcl:"method,optional"`
-	PathTemplate  string            `hcl:"path_template,label"`
-	Backend       BackendConfig     `hcl:"backend,block"`
-	FlushInterval time.Duration     `hcl:"flush_interval,optional"`
-	SetHeader     map[string]string `hcl:"set_header,optional"`
-	Transformer   DynamicConfig     `hcl:"transformer,block"`
+	Method             string                    `hcl:"method,optional"`
+	PathTemplate       string                    `hcl:"path_template,label"`
+	Match              *MatchConfig              `hcl:"match,block"`
+	Backend            BackendConfig             `hcl:"backend,block"`
+	FlushInterval      time.Duration             `hcl:"flush_interval,optional"`
+	SetHeader          map[string]string         `hcl:"set_header,optional"`
+	Filename           string                    `hcl:"filename,optional"`
+	Query              *QueryConfig              `hcl:"query,block"`
+	RequestHeaders     *RequestHeadersConfig     `hcl:"request_headers,block"`
+	ResponseHeaders    *ResponseHeadersConfig    `hcl:"response_headers,block"`
+	Transformers       []*DynamicConfig          `hcl:"transformer,block"`
+	RequestTransformer *RequestTransformerConfig `hcl:"request_transformer,block"`
+	Validator          *ValidatorConfig          `hcl:"validator,block"`
 }
 
 type StaticServiceConfig struct {
 	Method       string            `hcl:"method,optional"`
 	PathTemplate string            `hcl:"path_template,label"`
+	Match        *MatchConfig      `hcl:"match,block"`
 	SetHeader    map[string]string `hcl:"set_header,optional"`
 	ResponseCode int               `hcl:"response_code"`
 	Content      string            `hcl:"content,optional"`
 }
 
+type QueryConfig struct {
+	Allow  []string          `hcl:"allow,optional"`
+	Drop   []string          `hcl:"drop,optional"`
+	Rename map[string]string `hcl:"rename,optional"`
+	Set    map[string]string `hcl:"set,optional"`
+}
+
+type RequestHeadersConfig struct {
+	Allow     []string          `hcl:"allow,optional"`
+	Deny      []string          `hcl:"deny,optional"`
+	Set       map[string]string `hcl:"set,optional"`
+	Append    map[string]string `hcl:"append,optional"`
+	Forwarded string            `hcl:"forwarded,optional"`
+}
+
+type ResponseHeadersConfig struct {
+	Pass          []string `hcl:"pass"`
+	PreferBackend bool     `hcl:"prefer_backend,optional"`
+}
+
+type ValidatorConfig struct {
+	Schema string `hcl:"schema"`
+}
+
 type BackendConfig struct {
 	TargetURL              string `hcl:"target_url"`
 	SuccessHTTPStatusCodes []int  `hcl:"success_http_status_codes,optional"`
@@ -77,8 +123,20 @@ type DynamicConfig struct {
 }
 
 func (c *DynamicConfig) ToConfig() (Config, error) {
+	return attributesToConfig(c.Attributes)
+}
+
+type RequestTransformerConfig struct {
+	Attributes map[string]cty.Value `hcl:",remain"`
+}
+
+func (c *RequestTransformerConfig) ToConfig() (Config, error) {
+	return attributesToConfig(c.Attributes)
+}
+
+func attributesToConfig(attributes map[string]cty.Value) (Config, error) {
 	cfg := make(Config)
-	for name, v := range c.Attributes {
+	for name, v := range attributes {
 		goV, err := extractGoValues(v, v.Type())
 		if err != nil {
 			return nil, errors.Wrap(err, "cannot extract value")
@@ -1,8 +1,11 @@
 package bootstrap
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/qntfy/kazaam"
@@ -14,11 +17,27 @@ const (
 	RemapperReferenceName = "remapper"
 	RemapperKazaam        = "kazaam" // https://github.com/qntfy/kazaam
 	RemapperNoOp          = "noOp"
+	RemapperXML           = "xml"
+	RemapperJQ            = "jq"      // https://jqlang.github.io/jq/
+	RemapperJSONata       = "jsonata" // https://jsonata.org/
+	RemapperChain         = "chain"
+	RemapperJavaScript    = "javascript" // https://github.com/dop251/goja
+	RemapperWASM          = "wasm"
 )
 
 var Remapper = FactoryMap[manipulation.Remapper]{
-	RemapperKazaam: FactoryFunc[manipulation.Remapper](CreateRemapperKazaam),
-	RemapperNoOp:   FactoryFunc[manipulation.Remapper](CreateRemapperNoOp),
+	RemapperKazaam:     FactoryFunc[manipulation.Remapper](CreateRemapperKazaam),
+	RemapperNoOp:       FactoryFunc[manipulation.Remapper](CreateRemapperNoOp),
+	RemapperXML:        FactoryFunc[manipulation.Remapper](CreateRemapperXML),
+	RemapperJQ:         FactoryFunc[manipulation.Remapper](CreateRemapperJQ),
+	RemapperJSONata:    FactoryFunc[manipulation.Remapper](CreateRemapperJSONata),
+	RemapperJavaScript: FactoryFunc[manipulation.Remapper](CreateRemapperJavaScript),
+	RemapperWASM:       FactoryFunc[manipulation.Remapper](CreateRemapperWASM),
+}
+
+func init() {
+	// the chain remapper is registered here since it refers to the Remapper factory itself
+	Remapper[RemapperChain] = ChainRemapperFactory(Remapper)
 }
 
 func CreateRemapperKazaam(name string, config Config) (manipulation.Remapper, error) {
@@ -43,6 +62,14 @@ func CreateRemapperKazaam(name string, config Config) (manipulation.Remapper, er
 
 	const entryName = RemapperReferenceName + "." + RemapperKazaam
 
+	if strings.Contains(specString, "{{") {
+		remapper, err := manipulation.NewKazaamTemplateRemapper(specString)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: cannot create templated remapper", entryName)
+		}
+		return remapper, nil
+	}
+
 	kazaamInst, err := kazaam.NewKazaam(specString)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s: cannot create kazaam instance", entryName)
@@ -61,3 +88,172 @@ func CreateRemapperNoOp(name string, _ Config) (manipulation.Remapper, error) {
 	}
 	return manipulation.NewNoOpRemapper(), nil
 }
+
+func CreateRemapperXML(name string, config Config) (manipulation.Remapper, error) {
+	if name != RemapperXML {
+		return nil, fmt.Errorf(
+			"CreateRemapperXML: called with unexpected name '%s', want '%s'",
+			name,
+			RemapperXML,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = RemapperReferenceName + "." + RemapperXML
+
+	remapperConfig := manipulation.XMLRemapperConfig{AttributePrefix: "@"}
+	if err := decode(config, &remapperConfig); err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+	}
+	return manipulation.NewXMLRemapper(remapperConfig), nil
+}
+
+func CreateRemapperJQ(name string, config Config) (manipulation.Remapper, error) {
+	if name != RemapperJQ {
+		return nil, fmt.Errorf(
+			"CreateRemapperJQ: called with unexpected name '%s', want '%s'",
+			name,
+			RemapperJQ,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = RemapperReferenceName + "." + RemapperJQ
+
+	program := config.GetString("program")
+	if program == "" {
+		return nil, errRequiredConfiguration(entryName, "program")
+	}
+	remapper, err := manipulation.NewJQRemapper(program, config.pathParameters()...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid program", entryName)
+	}
+	return remapper, nil
+}
+
+func CreateRemapperJSONata(name string, config Config) (manipulation.Remapper, error) {
+	if name != RemapperJSONata {
+		return nil, fmt.Errorf(
+			"CreateRemapperJSONata: called with unexpected name '%s', want '%s'",
+			name,
+			RemapperJSONata,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = RemapperReferenceName + "." + RemapperJSONata
+
+	expression := config.GetString("expression")
+	if expression == "" {
+		return nil, errRequiredConfiguration(entryName, "expression")
+	}
+	remapper, err := manipulation.NewJSONataRemapper(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid expression", entryName)
+	}
+	return remapper, nil
+}
+
+// ChainRemapperFactory creates the remapper which pipes the data through the remappers
+// defined by the "steps" list, each step is created by means of the given factory
+func ChainRemapperFactory(remapperFactory Factory[manipulation.Remapper]) Factory[manipulation.Remapper] {
+	return FactoryFunc[manipulation.Remapper](func(name string, config Config) (manipulation.Remapper, error) {
+		if name != RemapperChain {
+			return nil, fmt.Errorf(
+				"CreateRemapperChain: called with unexpected name '%s', want '%s'",
+				name,
+				RemapperChain,
+			)
+		}
+		config, _ = extractConfigIfSet(name, config)
+		const entryName = RemapperReferenceName + "." + RemapperChain
+
+		var steps []Config
+		if err := decode(config["steps"], &steps); err != nil {
+			return nil, errors.Wrapf(err, "%s: cannot decode steps", entryName)
+		}
+		if len(steps) == 0 {
+			return nil, errRequiredConfiguration(entryName, "steps")
+		}
+		remappers := make([]manipulation.Remapper, len(steps))
+		for i, step := range steps {
+			step = inheritPathParameters(step, config)
+			remapper, err := remapperFactory.Create(step.GetString("name"), step)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s: cannot create remapper steps[%d]", entryName, i)
+			}
+			remappers[i] = remapper
+		}
+		return manipulation.NewChainRemapper(remappers...), nil
+	})
+}
+
+func CreateRemapperJavaScript(name string, config Config) (manipulation.Remapper, error) {
+	if name != RemapperJavaScript {
+		return nil, fmt.Errorf(
+			"CreateRemapperJavaScript: called with unexpected name '%s', want '%s'",
+			name,
+			RemapperJavaScript,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = RemapperReferenceName + "." + RemapperJavaScript
+
+	remapperConfig := manipulation.JavaScriptRemapperConfig{}
+	if err := decode(config, &remapperConfig); err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+	}
+	if remapperConfig.Script == "" {
+		return nil, errRequiredConfiguration(entryName, "script")
+	}
+	// the engine does not account memory per virtual machine, so the limit cannot be enforced
+	if _, ok := config["memory_limit"]; ok {
+		return nil, errors.Errorf("%s: memory_limit is not supported, use timeout to limit the execution", entryName)
+	}
+	remapper, err := manipulation.NewJavaScriptRemapper(remapperConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid script", entryName)
+	}
+	return remapper, nil
+}
+
+func CreateRemapperWASM(name string, config Config) (manipulation.Remapper, error) {
+	if name != RemapperWASM {
+		return nil, fmt.Errorf(
+			"CreateRemapperWASM: called with unexpected name '%s', want '%s'",
+			name,
+			RemapperWASM,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = RemapperReferenceName + "." + RemapperWASM
+
+	module, err := createWASMModule(entryName, config)
+	if err != nil {
+		return nil, err
+	}
+	remapper, err := manipulation.NewWASMRemapper(module)
+	if err != nil {
+		_ = module.Close(context.Background())
+		return nil, errors.Wrapf(err, "%s: invalid module", entryName)
+	}
+	return remapper, nil
+}
+
+// createWASMModule compiles the WebAssembly module located at the path given by the "module" attribute
+func createWASMModule(entryName string, config Config) (*manipulation.WASMModule, error) {
+	path := config.GetString("module")
+	if path == "" {
+		return nil, errRequiredConfiguration(entryName, "module")
+	}
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot read module", entryName)
+	}
+	moduleConfig := manipulation.WASMModuleConfig{}
+	if err = decode(config, &moduleConfig); err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+	}
+	module, err := manipulation.NewWASMModule(context.Background(), binary, moduleConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot load module '%s'", entryName, path)
+	}
+	return module, nil
+}
@@ -0,0 +1,16 @@
+package bootstrap
+
+import (
+	"testing"
+)
+
+func TestCreateRemapperJavaScriptMemoryLimit(t *testing.T) {
+	script := `function remap(input) { return input }`
+	if _, err := CreateRemapperJavaScript(RemapperJavaScript, Config{"script": script}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err := CreateRemapperJavaScript(RemapperJavaScript, Config{"script": script, "memory_limit": 16777216})
+	if err == nil {
+		t.Errorf("expected to return error for memory_limit which cannot be enforced")
+	}
+}
@@ -11,10 +11,14 @@ import (
 const (
 	TablifierReferenceName = "tablifier"
 	TablifierJSON          = "json"
+	TablifierCSV           = "csv"
+	TablifierXMLRows       = "xml_rows"
 )
 
 var Tablifier = FactoryMap[manipulation.Tablifier]{
-	TablifierJSON: JSONTablifierFactory(Remapper, manipulation.NewNoOpRemapper()),
+	TablifierJSON:    JSONTablifierFactory(Remapper, manipulation.NewNoOpRemapper()),
+	TablifierCSV:     FactoryFunc[manipulation.Tablifier](CreateCSVTablifier),
+	TablifierXMLRows: FactoryFunc[manipulation.Tablifier](CreateXMLRowsTablifier),
 }
 
 func JSONTablifierFactory(
@@ -48,3 +52,129 @@ func JSONTablifierFactory(
 		return manipulation.NewJSONTablifier(remapper, GetLogger(), tablifierConfig), nil
 	})
 }
+
+func CreateCSVTablifier(name string, config Config) (manipulation.Tablifier, error) {
+	if name != TablifierCSV {
+		return nil, fmt.Errorf(
+			"CreateCSVTablifier: called with unexpected name '%s', want '%s'",
+			name,
+			TablifierCSV,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = TablifierReferenceName + "." + TablifierCSV
+
+	tablifierConfig := manipulation.CSVTablifierConfig{HeaderRow: 1}
+	if err := decode(config, &tablifierConfig); err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+	}
+	tablifier, err := manipulation.NewCSVTablifier(tablifierConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot create tablifier", entryName)
+	}
+	return tablifier, nil
+}
+
+func CreateXMLRowsTablifier(name string, config Config) (manipulation.Tablifier, error) {
+	if name != TablifierXMLRows {
+		return nil, fmt.Errorf(
+			"CreateXMLRowsTablifier: called with unexpected name '%s', want '%s'",
+			name,
+			TablifierXMLRows,
+		)
+	}
+	config, _ = extractConfigIfSet(name, config)
+	const entryName = TablifierReferenceName + "." + TablifierXMLRows
+
+	tablifierConfig := manipulation.XMLRowsTablifierConfig{}
+	if err := decode(config, &tablifierConfig); err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+	}
+	tablifier, err := manipulation.NewXMLRowsTablifier(tablifierConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: cannot create tablifier", entryName)
+	}
+	return tablifier, nil
+}
+
+const (
+	TableOperationComputedColumns = "computed_columns"
+	TableOperationGroupBy         = "group_by"
+	TableOperationPivot           = "pivot"
+	TableOperationTranspose       = "transpose"
+	TableOperationTotals          = "totals"
+)
+
+// ProcessingTablifierFactory creates tablifiers by means of the given factory
+// and applies table operations defined in the tablifier configuration in the following order:
+// computed columns are added ("computed_columns"), rows are grouped ("group_by"),
+// the table is reshaped ("pivot", "transpose") and then the totals row is appended ("totals")
+func ProcessingTablifierFactory(tablifierFactory Factory[manipulation.Tablifier]) Factory[manipulation.Tablifier] {
+	return FactoryFunc[manipulation.Tablifier](func(name string, config Config) (manipulation.Tablifier, error) {
+		tablifier, err := tablifierFactory.Create(name, config)
+		if err != nil {
+			return nil, err
+		}
+		entryName := TablifierReferenceName + "." + name
+		operations, err := tableOperations(config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: cannot create table operations", entryName)
+		}
+		if len(operations) == 0 {
+			return tablifier, nil
+		}
+		return manipulation.NewProcessingTablifier(tablifier, operations...), nil
+	})
+}
+
+func tableOperations(config Config) ([]manipulation.TableOperation, error) {
+	var operations []manipulation.TableOperation
+	if computed, ok := config[TableOperationComputedColumns]; ok {
+		var cfg []manipulation.ComputedColumnConfig
+		if err := decode(computed, &cfg); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode '%s' configuration", TableOperationComputedColumns)
+		}
+		operation, err := manipulation.ComputedColumns(cfg)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	if groupByConfig, exist := extractConfigIfSet(TableOperationGroupBy, config); exist {
+		cfg := manipulation.GroupByConfig{}
+		if err := decode(groupByConfig, &cfg); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode '%s' configuration", TableOperationGroupBy)
+		}
+		operation, err := manipulation.GroupBy(cfg)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	if pivotConfig, exist := extractConfigIfSet(TableOperationPivot, config); exist {
+		cfg := manipulation.PivotConfig{}
+		if err := decode(pivotConfig, &cfg); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode '%s' configuration", TableOperationPivot)
+		}
+		operation, err := manipulation.Pivot(cfg)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	if transpose, ok := config[TableOperationTranspose].(bool); ok && transpose {
+		operations = append(operations, manipulation.Transpose())
+	}
+	if totalsConfig, exist := extractConfigIfSet(TableOperationTotals, config); exist {
+		cfg := manipulation.TotalsConfig{}
+		if err := decode(totalsConfig, &cfg); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode '%s' configuration", TableOperationTotals)
+		}
+		operation, err := manipulation.Totals(cfg)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
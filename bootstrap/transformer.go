@@ -1,11 +1,13 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/velmie/alternea/manipulation"
 
@@ -16,11 +18,20 @@ const (
 	TransformerReferenceName = "transformer"
 	TransformerCSV           = "csv"
 	TransformerPDF           = "pdf"
+	TransformerWASM          = "wasm"
+	TransformerExec          = "exec"
+	TransformerJSON          = "json"
 )
 
+// RequestTransformerReferenceName is the name of the block which configures the request body transformation
+const RequestTransformerReferenceName = "request_transformer"
+
 var Transformer = FactoryMap[manipulation.DataTransformer]{
-	TransformerCSV: CSVTransformer(Tablifier),
-	TransformerPDF: PDFTransformer(Remapper),
+	TransformerCSV:  CSVTransformer(ProcessingTablifierFactory(Tablifier)),
+	TransformerPDF:  PDFTransformer(Remapper, ProcessingTablifierFactory(Tablifier)),
+	TransformerWASM: FactoryFunc[manipulation.DataTransformer](CreateTransformerWASM),
+	TransformerExec: ExecTransformer(Remapper),
+	TransformerJSON: JSONTransformer(Remapper),
 }
 
 func CSVTransformer(tablifierFactory Factory[manipulation.Tablifier]) Factory[manipulation.DataTransformer] {
@@ -53,7 +64,10 @@ func CSVTransformer(tablifierFactory Factory[manipulation.Tablifier]) Factory[ma
 		})
 }
 
-func PDFTransformer(remapperFactory Factory[manipulation.Remapper]) Factory[manipulation.DataTransformer] {
+func PDFTransformer(
+	remapperFactory Factory[manipulation.Remapper],
+	tablifierFactory Factory[manipulation.Tablifier],
+) Factory[manipulation.DataTransformer] {
 	return FactoryFunc[manipulation.DataTransformer](
 		func(name string, config Config) (manipulation.DataTransformer, error) {
 			if name != TransformerPDF {
@@ -92,10 +106,86 @@ func PDFTransformer(remapperFactory Factory[manipulation.Remapper]) Factory[mani
 
 			pdfTransformer := manipulation.NewPDFTransformer(remapper, htmlTemplate)
 
+			if tablifierConfig, exist := extractConfigIfSet(TablifierReferenceName, config); exist {
+				tablifier, err := tablifierFactory.Create(tablifierConfig.GetString("name"), tablifierConfig)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s: cannot create %s", entryName, TablifierReferenceName)
+				}
+				pdfTransformer.SetTablifier(tablifier)
+			}
+
 			return pdfTransformer, nil
 		})
 }
 
+func CreateTransformerWASM(name string, config Config) (manipulation.DataTransformer, error) {
+	if name != TransformerWASM {
+		return nil, fmt.Errorf(
+			"CreateTransformerWASM: called with unexpected name '%s', want '%s'",
+			name,
+			TransformerWASM,
+		)
+	}
+	const entryName = TransformerReferenceName + "." + TransformerWASM
+
+	module, err := createWASMModule(entryName, config)
+	if err != nil {
+		return nil, err
+	}
+	transformer, err := manipulation.NewWASMTransformer(module)
+	if err != nil {
+		_ = module.Close(context.Background())
+		return nil, errors.Wrapf(err, "%s: invalid module", entryName)
+	}
+	transformer.SetMediaType(config.GetString("media_type"), config.GetString("extension"))
+	return transformer, nil
+}
+
+func ExecTransformer(remapperFactory Factory[manipulation.Remapper]) Factory[manipulation.DataTransformer] {
+	return FactoryFunc[manipulation.DataTransformer](
+		func(name string, config Config) (manipulation.DataTransformer, error) {
+			if name != TransformerExec {
+				return nil, fmt.Errorf(
+					"ExecTransformer: called with unexpected name '%s', want '%s'",
+					name,
+					TransformerExec,
+				)
+			}
+			const entryName = TransformerReferenceName + "." + TransformerExec
+
+			execConfig := manipulation.ExecTransformerConfig{}
+			if err := decode(config, &execConfig); err != nil {
+				return nil, errors.Wrapf(err, "%s: cannot decode configuration", entryName)
+			}
+			if execConfig.Command == "" {
+				return nil, errRequiredConfiguration(entryName, "command")
+			}
+			if _, err := execLookPath(execConfig.Command, execConfig.Dir); err != nil {
+				return nil, errors.Wrapf(err, "%s: cannot find command", entryName)
+			}
+
+			var remapper manipulation.Remapper
+			if remapperConfig, exist := extractConfigIfSet(RemapperReferenceName, config); exist {
+				var err error
+				remapper, err = remapperFactory.Create(remapperConfig.GetString("name"), remapperConfig)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s: cannot create remapper", entryName)
+				}
+			}
+
+			return manipulation.NewExecTransformer(remapper, GetLogger(), execConfig), nil
+		})
+}
+
+// execLookPath finds the command the same way as it is started, the relative path containing
+// a path separator is resolved against the working directory of the command
+func execLookPath(command, dir string) (string, error) {
+	if dir != "" && !filepath.IsAbs(command) && strings.ContainsRune(command, filepath.Separator) {
+		command = filepath.Join(dir, command)
+	}
+	return exec.LookPath(command)
+}
+
 func wkhtmltopdfFindPath() error {
 	const exe = "wkhtmltopdf"
 
@@ -121,3 +211,27 @@ func wkhtmltopdfFindPath() error {
 	}
 	return fmt.Errorf("%s not found", exe)
 }
+
+func JSONTransformer(remapperFactory Factory[manipulation.Remapper]) Factory[manipulation.DataTransformer] {
+	return FactoryFunc[manipulation.DataTransformer](
+		func(name string, config Config) (manipulation.DataTransformer, error) {
+			if name != TransformerJSON {
+				return nil, fmt.Errorf(
+					"JSONTransformer: called with unexpected name '%s', want '%s'",
+					name,
+					TransformerJSON,
+				)
+			}
+			const entryName = TransformerReferenceName + "." + TransformerJSON
+
+			var remapper manipulation.Remapper
+			if remapperConfig, exist := extractConfigIfSet(RemapperReferenceName, config); exist {
+				var err error
+				remapper, err = remapperFactory.Create(remapperConfig.GetString("name"), remapperConfig)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s: cannot create remapper", entryName)
+				}
+			}
+			return manipulation.NewJSONTransformer(remapper), nil
+		})
+}
@@ -72,12 +72,12 @@ func main() {
 	}
 
 	for _, serverConfig := range rootConfig.Servers {
-		if len(serverConfig.ProxyServices) == 0 && len(serverConfig.StaticServices) == 0 {
+		if len(serverConfig.ProxyServices) == 0 && len(serverConfig.StaticServices) == 0 && len(serverConfig.RouteGroups) == 0 {
 			log.Warningf("no services are defined for the server '%s'")
 			continue
 		}
 
-		router := route.NewDefaultRouter(route.NewWildCardMatcher)
+		router := route.NewConditionalRouter(route.NewTreeRouter())
 
 		if err = routesInitializer.InitRoutes(router, serverConfig); err != nil {
 			log.Errorf("cannot create server '%s': %s", serverConfig.Name, err)
@@ -0,0 +1,324 @@
+package dframe
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AggFunc reduces column values to a single value
+type AggFunc func(values []any) any
+
+// Aggregation describes which function should be applied to the column
+// As is the name of the resulting column, the column name is used if it is empty
+type Aggregation struct {
+	Column string
+	Func   AggFunc
+	As     string
+}
+
+const (
+	AggSum   = "sum"
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+	AggCount = "count"
+	AggFirst = "first"
+	AggLast  = "last"
+)
+
+var aggFuncs = map[string]AggFunc{
+	AggSum:   Sum,
+	AggAvg:   Avg,
+	AggMin:   Min,
+	AggMax:   Max,
+	AggCount: Count,
+	AggFirst: First,
+	AggLast:  Last,
+}
+
+// AggFuncByName returns one of the built-in aggregation functions
+func AggFuncByName(name string) (AggFunc, error) {
+	if f, ok := aggFuncs[strings.ToLower(name)]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unknown aggregation function %s", name)
+}
+
+// Sum returns the sum of the numeric values, non-numeric values are ignored
+func Sum(values []any) any {
+	sum := 0.0
+	for _, v := range values {
+		if f, ok := ToFloat(v); ok {
+			sum += f
+		}
+	}
+	return sum
+}
+
+// Avg returns the arithmetic mean of the numeric values, non-numeric values are ignored
+func Avg(values []any) any {
+	sum := 0.0
+	n := 0
+	for _, v := range values {
+		if f, ok := ToFloat(v); ok {
+			sum += f
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return sum / float64(n)
+}
+
+// Min returns the smallest value, numbers are compared numerically, everything else as strings
+func Min(values []any) any {
+	return extremum(values, -1)
+}
+
+// Max returns the largest value, numbers are compared numerically, everything else as strings
+func Max(values []any) any {
+	return extremum(values, 1)
+}
+
+// Count returns the number of non-nil values
+func Count(values []any) any {
+	n := 0
+	for _, v := range values {
+		if v != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// First returns the first non-nil value
+func First(values []any) any {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Last returns the last non-nil value
+func Last(values []any) any {
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] != nil {
+			return values[i]
+		}
+	}
+	return nil
+}
+
+// ToFloat converts the given value to float64 if it represents a number
+func ToFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Compare compares two values, numbers are compared numerically, everything else as strings
+// the result is -1 if a < b, 0 if a == b and 1 if a > b, nil is less than any other value
+func Compare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	fa, okA := ToFloat(a)
+	fb, okB := ToFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(defaultFormatter(a), defaultFormatter(b))
+}
+
+func extremum(values []any, sign int) any {
+	var result any
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if result == nil || Compare(v, result)*sign > 0 {
+			result = v
+		}
+	}
+	return result
+}
+
+// Grouping is an intermediate result of the Table.GroupBy
+type Grouping struct {
+	table *Table
+	keys  []*Column
+	err   error
+}
+
+// GroupBy groups table rows by the values of the given columns,
+// use Agg in order to get the resulting table
+func (t *Table) GroupBy(names ...string) *Grouping {
+	g := &Grouping{table: t}
+	for _, name := range names {
+		c, ok := t.Column(name)
+		if !ok {
+			g.err = fmt.Errorf("cannot group by unknown column %s", name)
+			return g
+		}
+		g.keys = append(g.keys, c)
+	}
+	return g
+}
+
+// Agg creates a new table which consists of the grouping columns followed by the aggregated columns,
+// groups are placed in the order of their first appearance
+func (g *Grouping) Agg(aggregations ...Aggregation) (*Table, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	t := g.table
+	sources := make([]*Column, len(aggregations))
+	for i, a := range aggregations {
+		c, ok := t.Column(a.Column)
+		if !ok {
+			return nil, fmt.Errorf("cannot aggregate unknown column %s", a.Column)
+		}
+		if a.Func == nil {
+			return nil, fmt.Errorf("aggregation function is not set for column %s", a.Column)
+		}
+		sources[i] = c
+	}
+
+	groupIndex := make(map[string]int)
+	var groups [][]int
+	for i := 0; i < t.numRows; i++ {
+		key := g.key(i)
+		n, ok := groupIndex[key]
+		if !ok {
+			n = len(groups)
+			groupIndex[key] = n
+			groups = append(groups, nil)
+		}
+		groups[n] = append(groups[n], i)
+	}
+
+	columns := make([]*Column, 0, len(g.keys)+len(aggregations))
+	for _, key := range g.keys {
+		c := NewColumn(key.name, Nullable, WithFormatter(key.formatter), WithNilPlaceholder(key.nilPlaceholder))
+		for _, rows := range groups {
+			c.Add(key.values[rows[0]])
+		}
+		columns = append(columns, c)
+	}
+	for i, a := range aggregations {
+		name := a.As
+		if name == "" {
+			name = a.Column
+		}
+		c := NewColumn(name, Nullable, WithNilPlaceholder(sources[i].nilPlaceholder))
+		values := make([]any, 0)
+		for _, rows := range groups {
+			values = values[:0]
+			for _, row := range rows {
+				values = append(values, sources[i].values[row])
+			}
+			c.Add(a.Func(values))
+		}
+		columns = append(columns, c)
+	}
+	return NewTable(columns...)
+}
+
+func (g *Grouping) key(row int) string {
+	if len(g.keys) == 1 {
+		return keyString(g.keys[0].values[row])
+	}
+	parts := make([]string, len(g.keys))
+	for i, c := range g.keys {
+		parts[i] = keyString(c.values[row])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// keyString formats the value prefixed with its kind, so that e.g. the number 1 and the string "1"
+// fall into different groups
+func keyString(v any) string {
+	var kind string
+	switch v.(type) {
+	case nil:
+		return "\x01"
+	case string:
+		kind = "s"
+	case bool:
+		kind = "b"
+	case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		kind = "n"
+	default:
+		kind = fmt.Sprintf("%T", v)
+	}
+	return kind + "\x02" + defaultFormatter(v)
+}
+
+// AppendTotals appends a row with the aggregated values of the given columns.
+// The label is written into the labelColumn (the first column if empty) unless the column is aggregated,
+// other cells of the row are left empty.
+func (t *Table) AppendTotals(label, labelColumn string, aggregations ...Aggregation) error {
+	if len(t.columns) == 0 {
+		return nil
+	}
+	row := make([]any, len(t.columns))
+	if label != "" {
+		i := 0
+		if labelColumn != "" {
+			var ok bool
+			if i, ok = t.nameIndex[labelColumn]; !ok {
+				return fmt.Errorf("unknown totals label column %s", labelColumn)
+			}
+		}
+		row[i] = label
+	}
+	for _, a := range aggregations {
+		i, ok := t.nameIndex[a.Column]
+		if !ok {
+			return fmt.Errorf("cannot aggregate unknown column %s", a.Column)
+		}
+		if a.Func == nil {
+			return fmt.Errorf("aggregation function is not set for column %s", a.Column)
+		}
+		row[i] = a.Func(t.columns[i].values[:t.numRows])
+	}
+	return t.AppendRow(row...)
+}
@@ -0,0 +1,97 @@
+package dframe
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func newTestTable(t *testing.T) *Table {
+	category := NewColumn("category", Nullable)
+	category.Add("food", "rent", "food", nil, "rent")
+	amount := NewColumn("amount", Nullable)
+	amount.Add(10.5, 700.0, 4.5, 1.0, "100")
+	table, err := NewTable(category, amount)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return table
+}
+
+type aggTest struct {
+	aggregations []Aggregation
+	header       []string
+	rows         [][]string
+}
+
+var aggTests = []aggTest{
+	{
+		aggregations: []Aggregation{{Column: "amount", Func: Sum}},
+		header:       []string{"category", "amount"},
+		rows:         [][]string{{"food", "15"}, {"rent", "800"}, {"", "1"}},
+	},
+	{
+		aggregations: []Aggregation{
+			{Column: "amount", Func: Count, As: "count"},
+			{Column: "amount", Func: Max, As: "max"},
+			{Column: "amount", Func: First, As: "first"},
+		},
+		header: []string{"category", "count", "max", "first"},
+		rows:   [][]string{{"food", "2", "10.5", "10.5"}, {"rent", "2", "700", "700"}, {"", "1", "1", "1"}},
+	},
+}
+
+func TestGroupByAgg(t *testing.T) {
+	for i, tt := range aggTests {
+		meta := fmt.Sprintf("test #%d: GroupBy(\"category\").Agg(%d aggregations),", i, len(tt.aggregations))
+		table, err := newTestTable(t).GroupBy("category").Agg(tt.aggregations...)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if header := table.Header(); !reflect.DeepEqual(header, tt.header) {
+			t.Errorf("%s expected header %+v, got %+v", meta, tt.header, header)
+		}
+		if rows := table.StringSlices(); !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("%s expected rows %+v, got %+v", meta, tt.rows, rows)
+		}
+	}
+}
+
+func TestGroupByValueKinds(t *testing.T) {
+	id := NewColumn("id", Nullable)
+	id.Add(1.0, "1", 1.0, true, "true")
+	table, err := NewTable(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	grouped, err := table.GroupBy("id").Agg(Aggregation{Column: "id", Func: Count, As: "count"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := [][]string{{"1", "2"}, {"1", "1"}, {"true", "1"}, {"true", "1"}}
+	if rows := grouped.StringSlices(); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("GroupBy(\"id\") expected rows %+v, got %+v", expected, rows)
+	}
+}
+
+func TestGroupByUnknownColumn(t *testing.T) {
+	if _, err := newTestTable(t).GroupBy("unknown").Agg(); err == nil {
+		t.Error("GroupBy(\"unknown\").Agg() expected to return error")
+	}
+}
+
+func TestAppendTotals(t *testing.T) {
+	table := newTestTable(t)
+	err := table.AppendTotals("Total", "", Aggregation{Column: "amount", Func: Sum})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if table.NumRows() != 6 {
+		t.Errorf("expected 6 rows, got %d", table.NumRows())
+	}
+	expected := []string{"Total", "816"}
+	if row := table.StringSlices()[5]; !reflect.DeepEqual(row, expected) {
+		t.Errorf("expected totals row %+v, got %+v", expected, row)
+	}
+}
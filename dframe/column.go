@@ -22,6 +22,10 @@ func NewColumn(name string, options ...ColumnOption) *Column {
 	return c
 }
 
+func (c *Column) Name() string {
+	return c.name
+}
+
 func (c *Column) Add(vals ...any) {
 	for _, v := range vals {
 		if c.nullable && v == nil {
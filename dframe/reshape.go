@@ -0,0 +1,113 @@
+package dframe
+
+import (
+	"fmt"
+)
+
+// Pivot creates a new table in which the distinct values of the index column become rows
+// and the distinct values of the columns column become columns, the cells hold the aggregated
+// values of the values column. Rows and columns are placed in the order of their first appearance.
+func (t *Table) Pivot(index, columns, values string, agg AggFunc) (*Table, error) {
+	indexCol, ok := t.Column(index)
+	if !ok {
+		return nil, fmt.Errorf("unknown pivot index column %s", index)
+	}
+	columnsCol, ok := t.Column(columns)
+	if !ok {
+		return nil, fmt.Errorf("unknown pivot columns column %s", columns)
+	}
+	valuesCol, ok := t.Column(values)
+	if !ok {
+		return nil, fmt.Errorf("unknown pivot values column %s", values)
+	}
+	if agg == nil {
+		return nil, fmt.Errorf("aggregation function is not set for pivot values column %s", values)
+	}
+
+	rowIndex := make(map[string]int)
+	var rowKeys []any
+	colIndex := make(map[string]int)
+	var colNames []string
+	cells := make(map[[2]int][]any)
+
+	for i := 0; i < t.numRows; i++ {
+		rowKey := keyString(indexCol.values[i])
+		r, ok := rowIndex[rowKey]
+		if !ok {
+			r = len(rowKeys)
+			rowIndex[rowKey] = r
+			rowKeys = append(rowKeys, indexCol.values[i])
+		}
+		colName := columnsCol.nilPlaceholder
+		if v := columnsCol.values[i]; v != nil {
+			colName = columnsCol.StringVal(i)
+		}
+		c, ok := colIndex[colName]
+		if !ok {
+			if colName == indexCol.name {
+				return nil, fmt.Errorf(
+					"pivot value '%s' of the column %s is equal to the name of the index column",
+					colName,
+					columnsCol.name,
+				)
+			}
+			c = len(colNames)
+			colIndex[colName] = c
+			colNames = append(colNames, colName)
+		}
+		cell := [2]int{r, c}
+		cells[cell] = append(cells[cell], valuesCol.values[i])
+	}
+
+	result := make([]*Column, 0, len(colNames)+1)
+	indexResult := NewColumn(indexCol.name, Nullable, WithFormatter(indexCol.formatter), WithNilPlaceholder(indexCol.nilPlaceholder))
+	indexResult.Add(rowKeys...)
+	result = append(result, indexResult)
+	for c, name := range colNames {
+		column := NewColumn(name, Nullable, WithNilPlaceholder(valuesCol.nilPlaceholder))
+		for r := range rowKeys {
+			cellValues, ok := cells[[2]int{r, c}]
+			if !ok {
+				column.Add(nil)
+				continue
+			}
+			column.Add(agg(cellValues))
+		}
+		result = append(result, column)
+	}
+	return NewTable(result...)
+}
+
+// Transpose creates a new table in which rows become columns and columns become rows.
+// The first column of the table provides names of the resulting columns,
+// its name is used for the resulting first column which holds names of the rest of the columns,
+// so the values of the first column must be unique and differ from its name.
+func (t *Table) Transpose() (*Table, error) {
+	if len(t.columns) == 0 {
+		return NewTable()
+	}
+	first := t.columns[0]
+	names := NewColumn(first.name, Nullable)
+	for _, c := range t.columns[1:] {
+		names.Add(c.name)
+	}
+	result := []*Column{names}
+	seen := map[string]bool{first.name: true}
+	for i := 0; i < t.numRows; i++ {
+		name := first.StringVal(i)
+		if seen[name] {
+			return nil, fmt.Errorf(
+				"cannot transpose: value '%s' of the column %s is used as a column name more than once",
+				name,
+				first.name,
+			)
+		}
+		seen[name] = true
+		column := NewColumn(name, Nullable)
+		for _, c := range t.columns[1:] {
+			column.Add(c.values[i])
+		}
+		result = append(result, column)
+	}
+	return NewTable(result...)
+}
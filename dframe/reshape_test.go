@@ -0,0 +1,79 @@
+package dframe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTransactionsTable(t *testing.T) *Table {
+	category := NewColumn("category")
+	category.Add("food", "food", "rent", "food")
+	month := NewColumn("month")
+	month.Add("Jan", "Feb", "Jan", "Jan")
+	amount := NewColumn("amount")
+	amount.Add(10.0, 20.0, 700.0, 5.0)
+	table, err := NewTable(category, month, amount)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return table
+}
+
+func TestPivot(t *testing.T) {
+	table, err := newTransactionsTable(t).Pivot("category", "month", "amount", Sum)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedHeader := []string{"category", "Jan", "Feb"}
+	if header := table.Header(); !reflect.DeepEqual(header, expectedHeader) {
+		t.Errorf("expected header %+v, got %+v", expectedHeader, header)
+	}
+	expectedRows := [][]string{{"food", "15", "20"}, {"rent", "700", ""}}
+	if rows := table.StringSlices(); !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("expected rows %+v, got %+v", expectedRows, rows)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	table, err := newTransactionsTable(t).Pivot("category", "month", "amount", Sum)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if table, err = table.Transpose(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedHeader := []string{"category", "food", "rent"}
+	if header := table.Header(); !reflect.DeepEqual(header, expectedHeader) {
+		t.Errorf("expected header %+v, got %+v", expectedHeader, header)
+	}
+	expectedRows := [][]string{{"Jan", "15", "700"}, {"Feb", "20", ""}}
+	if rows := table.StringSlices(); !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("expected rows %+v, got %+v", expectedRows, rows)
+	}
+}
+
+func TestReshapeDuplicateNames(t *testing.T) {
+	month := NewColumn("month", Nullable)
+	month.Add("Jan", "Jan")
+	amount := NewColumn("amount", Nullable)
+	amount.Add(1.0, 2.0)
+	table, err := NewTable(month, amount)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = table.Transpose(); err == nil || !strings.Contains(err.Error(), "'Jan'") {
+		t.Errorf("Transpose() expected to return error naming the duplicate value, got %v", err)
+	}
+	if _, err = table.Pivot("amount", "month", "amount", Sum); err != nil {
+		t.Errorf("Pivot() unexpected error: %s", err)
+	}
+	month.Add("amount")
+	amount.Add(3.0)
+	if table, err = NewTable(month, amount); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = table.Pivot("amount", "month", "amount", Sum); err == nil || !strings.Contains(err.Error(), "'amount'") {
+		t.Errorf("Pivot() expected to return error naming the value equal to the index column, got %v", err)
+	}
+}
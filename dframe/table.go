@@ -79,6 +79,81 @@ func (t *Table) Header() []string {
 	return names
 }
 
+// Column returns the column with the given name
+func (t *Table) Column(name string) (*Column, bool) {
+	i, ok := t.nameIndex[name]
+	if !ok {
+		return nil, false
+	}
+	return t.columns[i], true
+}
+
+// Row returns the row values keyed by the column names
+func (t *Table) Row(index int) map[string]any {
+	row := make(map[string]any, len(t.columns))
+	for _, c := range t.columns {
+		row[c.name] = c.values[index]
+	}
+	return row
+}
+
+// Set replaces the column with the same name or appends the column if there is no such column
+func (t *Table) Set(c *Column) error {
+	i, ok := t.nameIndex[c.name]
+	if !ok {
+		return t.Append(c)
+	}
+	if len(c.values) != t.numRows {
+		return fmt.Errorf("column %s has %d values, but the table has %d rows", c.name, len(c.values), t.numRows)
+	}
+	t.columns[i] = c
+	return nil
+}
+
+// AppendRow adds a row to the table, values are assigned to the columns in order
+func (t *Table) AppendRow(values ...any) error {
+	if len(values) != len(t.columns) {
+		return fmt.Errorf("row has %d values, but the table has %d columns", len(values), len(t.columns))
+	}
+	for i, c := range t.columns {
+		c.values = append(c.values[:t.numRows], values[i])
+	}
+	t.numRows++
+	return nil
+}
+
+// Concat appends rows of the other table, columns are matched by name.
+// Columns which exist only in one of the tables are filled with nil values for the rows
+// of the other table, that is why such columns must be nullable.
+func (t *Table) Concat(other *Table) error {
+	if other.numRows > 0 {
+		for _, c := range t.columns {
+			if _, ok := other.nameIndex[c.name]; !ok && !c.nullable {
+				return fmt.Errorf("cannot concat tables because the column %s is missing and is not nullable", c.name)
+			}
+		}
+	}
+	for _, c := range other.columns {
+		if _, ok := t.nameIndex[c.name]; ok {
+			continue
+		}
+		column := NewColumn(c.name, Nullable, WithFormatter(c.formatter), WithNilPlaceholder(c.nilPlaceholder))
+		column.values = make([]any, t.numRows)
+		t.columns = append(t.columns, column)
+		t.nameIndex[c.name] = len(t.columns) - 1
+	}
+	for _, c := range t.columns {
+		c.values = c.values[:t.numRows]
+		if i, ok := other.nameIndex[c.name]; ok {
+			c.values = append(c.values, other.columns[i].values[:other.numRows]...)
+			continue
+		}
+		c.values = append(c.values, make([]any, other.numRows)...)
+	}
+	t.numRows += other.numRows
+	return nil
+}
+
 func (t *Table) Select(names ...string) error {
 	columns := make([]*Column, 0, len(names))
 	for _, name := range names {
@@ -0,0 +1,44 @@
+package dframe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	id := NewColumn("id", Nullable)
+	id.Add(1, 2)
+	name := NewColumn("name", Nullable)
+	name.Add("a", "b")
+	table, _ := NewTable(id, name)
+
+	otherID := NewColumn("id", Nullable)
+	otherID.Add(3)
+	email := NewColumn("email", Nullable)
+	email.Add("c@example.com")
+	other, _ := NewTable(otherID, email)
+
+	if err := table.Concat(other); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedHeader := []string{"id", "name", "email"}
+	if header := table.Header(); !reflect.DeepEqual(header, expectedHeader) {
+		t.Errorf("expected header %+v, got %+v", expectedHeader, header)
+	}
+	expectedRows := [][]string{{"1", "a", ""}, {"2", "b", ""}, {"3", "", "c@example.com"}}
+	if rows := table.StringSlices(); !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("expected rows %+v, got %+v", expectedRows, rows)
+	}
+}
+
+func TestConcatNotNullable(t *testing.T) {
+	id := NewColumn("id")
+	id.Add(1)
+	table, _ := NewTable(id)
+	name := NewColumn("name")
+	name.Add("a")
+	other, _ := NewTable(name)
+	if err := table.Concat(other); err == nil {
+		t.Error("Concat expected to return error for missing not nullable column")
+	}
+}
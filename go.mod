@@ -4,26 +4,35 @@ go 1.19
 
 require (
 	github.com/SebastiaanKlippert/go-wkhtmltopdf v1.7.2
+	github.com/blues/jsonata-go v1.5.4
+	github.com/dop251/goja v0.0.0-20230812105242-81d76064690d
 	github.com/hashicorp/hcl/v2 v2.14.0
 	github.com/iancoleman/strcase v0.2.0
+	github.com/itchyny/gojq v0.12.13
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/pkg/errors v0.9.1
 	github.com/qntfy/kazaam v3.4.9+incompatible
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/sirupsen/logrus v1.9.0
+	github.com/tetratelabs/wazero v1.5.0
 	github.com/tidwall/gjson v1.14.3
 	github.com/zclconf/go-cty v1.11.0
 	golang.org/x/sync v0.0.0-20220907140024-f12130a52804
+	golang.org/x/text v0.3.8
 )
 
 require (
 	github.com/agext/levenshtein v1.2.1 // indirect
 	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/dlclark/regexp2 v1.7.0 // indirect
+	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
 	github.com/gofrs/uuid v4.3.0+incompatible // indirect
-	github.com/google/go-cmp v0.3.1 // indirect
+	github.com/google/go-cmp v0.5.4 // indirect
+	github.com/google/pprof v0.0.0-20230207041349-798e818bf904 // indirect
+	github.com/itchyny/timefmt-go v0.1.5 // indirect
 	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
 	github.com/qntfy/jsonparser v1.0.2 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
-	golang.org/x/text v0.3.7 // indirect
+	golang.org/x/sys v0.8.0 // indirect
 )
@@ -0,0 +1,29 @@
+package manipulation
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ChainRemapper pipes the data through the remappers in order, the output of each remapper is the input of the next one
+type ChainRemapper struct {
+	remappers []Remapper
+}
+
+func NewChainRemapper(remappers ...Remapper) *ChainRemapper {
+	return &ChainRemapper{remappers: remappers}
+}
+
+func (r *ChainRemapper) Remap(ctx context.Context, in []byte) ([]byte, error) {
+	var err error
+	for i, remapper := range r.remappers {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if in, err = remapper.Remap(ctx, in); err != nil {
+			return nil, errors.Wrapf(err, "ChainRemapper: steps[%d] failed", i)
+		}
+	}
+	return in, nil
+}
@@ -0,0 +1,39 @@
+package manipulation
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestChainRemapper(t *testing.T) {
+	first, err := NewJQRemapper(`{items: [.data[] | {title, price}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	second, err := NewJSONataRemapper(`$sum(items.price)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	remapper := NewChainRemapper(NewNoOpRemapper(), first, second)
+	out, err := remapper.Remap(context.Background(), []byte(`{"data":[{"title":"a","price":5},{"title":"b","price":15}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(out) != "20" {
+		t.Errorf("expected 20, got %s", out)
+	}
+
+	failing, err := NewJQRemapper(`error("boom")`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	remapper = NewChainRemapper(first, failing, second)
+	_, err = remapper.Remap(context.Background(), []byte(`{"data":[]}`))
+	if err == nil {
+		t.Fatal("expected error from the failing step")
+	}
+	if !strings.Contains(err.Error(), "steps[1]") {
+		t.Errorf("expected error to refer to steps[1], got %s", err)
+	}
+}
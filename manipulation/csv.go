@@ -7,12 +7,19 @@ import (
 	"unicode/utf8"
 
 	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/dframe"
 )
 
+// DefaultAccumulateMaxRows limits the number of accumulated rows unless another limit is configured
+const DefaultAccumulateMaxRows = 100000
+
 type CSVTransformerConfig struct {
-	UseHeader bool   // True to use header as a first line
-	Delimiter string // Field delimiter (set to ',' by default)
-	UseCRLF   bool   // True to use \r\n as the line terminator
+	UseHeader  bool   // True to use header as a first line
+	Delimiter  string // Field delimiter (set to ',' by default)
+	UseCRLF    bool   // True to use \r\n as the line terminator
+	Accumulate bool   // True to merge all pages into a single table before writing
+	MaxRows    int    // Maximum number of accumulated rows (set to DefaultAccumulateMaxRows by default)
 }
 
 type CSVTransformer struct {
@@ -24,6 +31,21 @@ func NewCSVTransformer(tablifier Tablifier, cfg CSVTransformerConfig) *CSVTransf
 	return &CSVTransformer{cfg, tablifier}
 }
 
+// MediaType returns "text/tab-separated-values" if the delimiter is tab and "text/csv" otherwise
+func (t *CSVTransformer) MediaType() string {
+	if t.config.Delimiter == "\t" {
+		return "text/tab-separated-values; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (t *CSVTransformer) FileExtension() string {
+	if t.config.Delimiter == "\t" {
+		return ".tsv"
+	}
+	return ".csv"
+}
+
 func (t *CSVTransformer) Transform(ctx context.Context, pages <-chan []byte, w io.Writer) error {
 	csvWriter := csv.NewWriter(w)
 	if t.config.Delimiter != "" {
@@ -33,21 +55,67 @@ func (t *CSVTransformer) Transform(ctx context.Context, pages <-chan []byte, w i
 	if t.config.UseCRLF {
 		csvWriter.UseCRLF = true
 	}
+	if t.config.Accumulate {
+		return t.accumulate(ctx, pages, csvWriter)
+	}
 	headerSet := false
 	for page := range pages {
-		table, err := t.tablifier.Table(page)
+		table, err := t.tablifier.Table(ctx, page)
+		if err != nil {
+			return errors.Wrap(err, "CSVTransformer: cannot get table")
+		}
+		if err = t.write(csvWriter, table, !headerSet); err != nil {
+			return err
+		}
+		headerSet = true
+	}
+	return nil
+}
+
+// accumulate merges tables of all pages, table operations of the ProcessingTablifier
+// are applied to the resulting table instead of each page
+func (t *CSVTransformer) accumulate(ctx context.Context, pages <-chan []byte, csvWriter *csv.Writer) error {
+	tablifier := t.tablifier
+	process := func(table *dframe.Table) (*dframe.Table, error) {
+		return table, nil
+	}
+	if processing, ok := tablifier.(*ProcessingTablifier); ok {
+		tablifier = processing.tablifier
+		process = processing.Process
+	}
+	maxRows := t.config.MaxRows
+	if maxRows <= 0 {
+		maxRows = DefaultAccumulateMaxRows
+	}
+
+	result, _ := dframe.NewTable()
+	for page := range pages {
+		table, err := tablifier.Table(ctx, page)
 		if err != nil {
 			return errors.Wrap(err, "CSVTransformer: cannot get table")
 		}
-		if t.config.UseHeader && !headerSet {
-			headerSet = true
-			if err = csvWriter.Write(table.Header()); err != nil {
-				return errors.Wrap(err, "CSVTransformer: cannot write header")
-			}
+		if result.NumRows()+table.NumRows() > maxRows {
+			return errors.Wrapf(ErrRowLimitExceeded, "CSVTransformer: more than %d rows accumulated", maxRows)
 		}
-		if err = csvWriter.WriteAll(table.StringSlices()); err != nil {
-			return errors.Wrap(err, "CSVTransformer: cannot write table")
+		if err = result.Concat(table); err != nil {
+			return errors.Wrap(err, "CSVTransformer: cannot accumulate table")
 		}
 	}
+	result, err := process(result)
+	if err != nil {
+		return errors.Wrap(err, "CSVTransformer: cannot process accumulated table")
+	}
+	return t.write(csvWriter, result, true)
+}
+
+func (t *CSVTransformer) write(csvWriter *csv.Writer, table *dframe.Table, withHeader bool) error {
+	if t.config.UseHeader && withHeader {
+		if err := csvWriter.Write(table.Header()); err != nil {
+			return errors.Wrap(err, "CSVTransformer: cannot write header")
+		}
+	}
+	if err := csvWriter.WriteAll(table.StringSlices()); err != nil {
+		return errors.Wrap(err, "CSVTransformer: cannot write table")
+	}
 	return nil
 }
@@ -0,0 +1,92 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/app"
+	"github.com/velmie/alternea/dframe"
+)
+
+func TestCSVTransformerAccumulate(t *testing.T) {
+	groupBy, err := GroupBy(GroupByConfig{
+		Columns:   []string{"region"},
+		Aggregate: []AggregationConfig{{Column: "amount", Func: "sum"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cases := []struct {
+		operations []TableOperation
+		pages      []string
+		expected   string
+	}{
+		{
+			pages: []string{
+				`{"region": ["eu", "us"], "amount": [1, 2]}`,
+				`{"region": ["eu"], "note": ["late"], "amount": [3]}`,
+				`{"amount": [4]}`,
+			},
+			expected: "region,amount,note\neu,1,\nus,2,\neu,3,late\n,4,\n",
+		},
+		{
+			operations: []TableOperation{groupBy},
+			pages: []string{
+				`{"region": ["eu", "us"], "amount": [1, 2]}`,
+				`{"region": ["eu"], "amount": [3]}`,
+			},
+			expected: "region,amount\neu,4\nus,2\n",
+		},
+	}
+	for i, c := range cases {
+		meta := fmt.Sprintf("test #%d: %v", i, c.pages)
+		applied := 0
+		operations := append([]TableOperation{
+			TableOperationFunc(func(table *dframe.Table) (*dframe.Table, error) {
+				applied++
+				return table, nil
+			}),
+		}, c.operations...)
+		tablifier := NewProcessingTablifier(NewJSONTablifier(NewNoOpRemapper(), app.NewNoopLogger(), nil), operations...)
+		transformer := NewCSVTransformer(tablifier, CSVTransformerConfig{UseHeader: true, Accumulate: true})
+		w := &bytes.Buffer{}
+		if err = transformer.Transform(context.Background(), execPages(c.pages...), w); err != nil {
+			t.Errorf("%s: unexpected error: %s", meta, err)
+			continue
+		}
+		if w.String() != c.expected {
+			t.Errorf("%s: expected %q, got %q", meta, c.expected, w.String())
+		}
+		if applied != 1 {
+			t.Errorf("%s: expected the operations to be applied once, applied %d times", meta, applied)
+		}
+	}
+}
+
+func TestCSVTransformerAccumulateMaxRows(t *testing.T) {
+	tablifier := NewJSONTablifier(NewNoOpRemapper(), app.NewNoopLogger(), nil)
+	pages := []string{`{"id": [1, 2]}`, `{"id": [3]}`}
+
+	transformer := NewCSVTransformer(tablifier, CSVTransformerConfig{Accumulate: true, MaxRows: 3})
+	w := &bytes.Buffer{}
+	if err := transformer.Transform(context.Background(), execPages(pages...), w); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "1\n2\n3\n"; w.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.String())
+	}
+
+	transformer = NewCSVTransformer(tablifier, CSVTransformerConfig{Accumulate: true, MaxRows: 2})
+	w = &bytes.Buffer{}
+	err := transformer.Transform(context.Background(), execPages(pages...), w)
+	if errors.Cause(err) != ErrRowLimitExceeded {
+		t.Errorf("expected %s, got %v", ErrRowLimitExceeded, err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %q", w.String())
+	}
+}
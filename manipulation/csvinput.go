@@ -0,0 +1,184 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+
+	"github.com/velmie/alternea/dframe"
+)
+
+// NoQuote disables quoting when used as CSVTablifierConfig.Quote
+const NoQuote = "none"
+
+type CSVTablifierConfig struct {
+	Delimiter string   // Field delimiter (set to ',' by default)
+	Quote     string   // Quote character (set to '"' by default), NoQuote disables quoting
+	HeaderRow int      // Number of the row (starting from 1) which holds column names, 0 means there is no header
+	Encoding  string   // Input encoding e.g. "windows-1251" (set to "utf-8" by default)
+	Columns   []string // Columns to select, column names are "column_1", "column_2", etc. if there is no header
+}
+
+// CSVTablifier creates table from delimiter-separated values such as CSV or TSV,
+// rows before the header row are skipped
+type CSVTablifier struct {
+	config    CSVTablifierConfig
+	delimiter rune
+	quote     rune
+	encoding  encoding.Encoding
+}
+
+func NewCSVTablifier(config CSVTablifierConfig) (*CSVTablifier, error) {
+	t := &CSVTablifier{config: config, delimiter: ',', quote: '"'}
+	if config.Delimiter != "" {
+		t.delimiter, _ = utf8.DecodeRuneInString(config.Delimiter)
+	}
+	switch config.Quote {
+	case "":
+	case NoQuote:
+		t.quote = 0
+	default:
+		t.quote, _ = utf8.DecodeRuneInString(config.Quote)
+	}
+	if t.delimiter == t.quote || t.delimiter == '\n' || t.delimiter == '\r' {
+		return nil, fmt.Errorf("CSVTablifier: invalid delimiter %q", t.delimiter)
+	}
+	if config.HeaderRow < 0 {
+		return nil, fmt.Errorf("CSVTablifier: invalid header row %d", config.HeaderRow)
+	}
+	if config.Encoding != "" {
+		enc, err := htmlindex.Get(config.Encoding)
+		if err != nil {
+			return nil, errors.Wrapf(err, "CSVTablifier: unsupported encoding '%s'", config.Encoding)
+		}
+		t.encoding = enc
+	}
+	return t, nil
+}
+
+func (t *CSVTablifier) Table(_ context.Context, in []byte) (*dframe.Table, error) {
+	if t.encoding != nil {
+		decoded, err := t.encoding.NewDecoder().Bytes(in)
+		if err != nil {
+			return nil, errors.Wrap(err, "CSVTablifier: cannot decode input")
+		}
+		in = decoded
+	}
+	in = bytes.TrimPrefix(in, []byte("\xEF\xBB\xBF"))
+
+	records, err := t.parse(string(in))
+	if err != nil {
+		return nil, err
+	}
+
+	var header []string
+	if t.config.HeaderRow > 0 {
+		if len(records) < t.config.HeaderRow {
+			return dframe.NewTable()
+		}
+		header = records[t.config.HeaderRow-1]
+		records = records[t.config.HeaderRow:]
+	}
+	numColumns := len(header)
+	for _, record := range records {
+		if len(record) > numColumns {
+			numColumns = len(record)
+		}
+	}
+	columns := make([]*dframe.Column, numColumns)
+	for i := range columns {
+		name := fmt.Sprintf("column_%d", i+1)
+		if i < len(header) && header[i] != "" {
+			name = header[i]
+		}
+		columns[i] = dframe.NewColumn(name, dframe.Nullable)
+		for _, record := range records {
+			if i < len(record) {
+				columns[i].Add(record[i])
+			} else {
+				columns[i].Add(nil)
+			}
+		}
+	}
+	table, err := dframe.NewTable(columns...)
+	if err != nil {
+		return nil, errors.Wrap(err, "CSVTablifier: cannot create table")
+	}
+	if len(t.config.Columns) > 0 {
+		if err = table.Select(t.config.Columns...); err != nil {
+			return nil, errors.Wrap(err, "CSVTablifier: cannot select columns")
+		}
+	}
+	return table, nil
+}
+
+// parse splits the input into records, it follows RFC 4180 but allows any delimiter and quote characters:
+// a quoted field may contain delimiters and line breaks, a quote inside it is escaped by doubling
+func (t *CSVTablifier) parse(in string) ([][]string, error) {
+	var (
+		records [][]string
+		record  []string
+		field   strings.Builder
+		line    = 1
+	)
+	endRecord := func() {
+		record = append(record, field.String())
+		field.Reset()
+		if len(record) > 1 || record[0] != "" {
+			records = append(records, record)
+		}
+		record = nil
+	}
+	quoted := false
+	fieldStart := true
+	for i := 0; i < len(in); {
+		r, size := utf8.DecodeRuneInString(in[i:])
+		i += size
+		switch {
+		case quoted:
+			if r == t.quote {
+				next, nextSize := utf8.DecodeRuneInString(in[i:])
+				if i < len(in) && next == t.quote {
+					field.WriteRune(r)
+					i += nextSize
+					continue
+				}
+				quoted = false
+				continue
+			}
+			if r == '\n' {
+				line++
+			}
+			field.WriteRune(r)
+		case r == t.quote && t.quote != 0 && fieldStart:
+			quoted = true
+			fieldStart = false
+		case r == t.delimiter:
+			record = append(record, field.String())
+			field.Reset()
+			fieldStart = true
+		case r == '\r' && strings.HasPrefix(in[i:], "\n"):
+			// \r\n is handled as \n
+		case r == '\n':
+			endRecord()
+			fieldStart = true
+			line++
+		default:
+			field.WriteRune(r)
+			fieldStart = false
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("CSVTablifier: line %d: unterminated quoted field", line)
+	}
+	if field.Len() > 0 || len(record) > 0 {
+		endRecord()
+	}
+	return records, nil
+}
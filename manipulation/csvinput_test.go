@@ -0,0 +1,64 @@
+package manipulation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type csvTablifierTest struct {
+	config CSVTablifierConfig
+	in     string
+	header []string
+	rows   [][]string
+}
+
+var csvTablifierTests = []csvTablifierTest{
+	{
+		config: CSVTablifierConfig{HeaderRow: 1},
+		in:     "id,name\r\n1,\"Turing, Alan\"\r\n2,\"say \"\"hi\"\"\"\r\n",
+		header: []string{"id", "name"},
+		rows:   [][]string{{"1", "Turing, Alan"}, {"2", `say "hi"`}},
+	},
+	{
+		config: CSVTablifierConfig{Delimiter: "\t", Quote: NoQuote},
+		in:     "1\t\"a\n2\tb\tc",
+		header: []string{"column_1", "column_2", "column_3"},
+		rows:   [][]string{{"1", `"a`, ""}, {"2", "b", "c"}},
+	},
+	{
+		config: CSVTablifierConfig{HeaderRow: 2, Delimiter: ";", Quote: "'", Columns: []string{"b"}},
+		in:     "report\na;b\n1;'x;\ny'\n",
+		header: []string{"b"},
+		rows:   [][]string{{"x;\ny"}},
+	},
+	{
+		config: CSVTablifierConfig{HeaderRow: 1, Encoding: "windows-1251"},
+		in:     "name\n\xcf\xf0\xe8\xe2\xe5\xf2\n",
+		header: []string{"name"},
+		rows:   [][]string{{"Привет"}},
+	},
+}
+
+func TestCSVTablifier(t *testing.T) {
+	for i, tt := range csvTablifierTests {
+		meta := fmt.Sprintf("test #%d: CSVTablifier(%+v).Table(%q),", i, tt.config, tt.in)
+		tablifier, err := NewCSVTablifier(tt.config)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		table, err := tablifier.Table(context.Background(), []byte(tt.in))
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if header := table.Header(); !reflect.DeepEqual(header, tt.header) {
+			t.Errorf("%s expected header %+v, got %+v", meta, tt.header, header)
+		}
+		if rows := table.StringSlices(); !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("%s expected rows %+v, got %+v", meta, tt.rows, rows)
+		}
+	}
+}
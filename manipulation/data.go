@@ -8,11 +8,16 @@ import (
 )
 
 type Tablifier interface {
-	Table(in []byte) (*dframe.Table, error)
+	Table(ctx context.Context, in []byte) (*dframe.Table, error)
 }
 
 type Remapper interface {
-	Remap(in []byte) ([]byte, error)
+	Remap(ctx context.Context, in []byte) ([]byte, error)
+}
+
+// Validator checks that the input data conforms to the expected format
+type Validator interface {
+	Validate(ctx context.Context, in []byte) error
 }
 
 type (
@@ -20,4 +25,12 @@ type (
 	DataTransformer interface {
 		Transform(ctx context.Context, pages <-chan []byte, w io.Writer) error
 	}
+	// MediaTypeProvider is implemented by the data transformers which produce data of a particular format,
+	// an empty value means that the format is unknown
+	MediaTypeProvider interface {
+		// MediaType returns the media type of the produced data e.g. "application/pdf"
+		MediaType() string
+		// FileExtension returns the file name extension of the produced data including the dot e.g. ".pdf"
+		FileExtension() string
+	}
 )
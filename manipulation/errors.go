@@ -8,4 +8,6 @@ func (e Error) Error() string {
 
 const (
 	ErrUnsupportedDataType = Error("unsupported data type")
+	ErrRowLimitExceeded    = Error("row limit exceeded")
+	ErrExecutionTimeout    = Error("execution timeout exceeded")
 )
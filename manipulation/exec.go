@@ -0,0 +1,202 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/app"
+)
+
+// execStderrTailSize is the number of the last stderr bytes included in the error when the command fails
+const execStderrTailSize = 1024
+
+type ExecTransformerConfig struct {
+	Command string            // Command to execute, looked up in PATH if it does not contain a path separator
+	Args    []string          // Command arguments
+	Env     map[string]string // Environment variables added to the command environment
+	// PassEnv lists the environment variables of alternea which are passed to the command
+	PassEnv []string
+	// InheritEnv set to true to pass the whole environment of alternea to the command
+	InheritEnv bool
+	// Dir is the working directory of the command, the current directory is used if empty
+	Dir string
+	// Timeout limits the execution time of the command, 0 means no limit
+	Timeout time.Duration
+	// MaxProcesses limits the number of concurrently running commands (set to the number of CPUs by default),
+	// requests wait for a free slot until their context is done
+	MaxProcesses int
+	// MediaType and Extension describe the output of the command e.g. "application/zip" and ".zip"
+	MediaType string
+	Extension string
+}
+
+// ExecTransformer spawns the command per request, writes the page data to its stdin
+// and copies its stdout to the writer, stderr of the command is logged
+type ExecTransformer struct {
+	config    ExecTransformerConfig
+	remapper  Remapper
+	logger    app.Logger
+	env       []string
+	processes chan struct{}
+}
+
+func NewExecTransformer(remapper Remapper, logger app.Logger, cfg ExecTransformerConfig) *ExecTransformer {
+	if cfg.MaxProcesses <= 0 {
+		cfg.MaxProcesses = runtime.NumCPU()
+	}
+	var env []string
+	if cfg.InheritEnv {
+		env = os.Environ()
+	} else {
+		for _, name := range cfg.PassEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	}
+	for name, value := range cfg.Env {
+		env = append(env, name+"="+value)
+	}
+	if env == nil {
+		// nil would mean the environment of the current process
+		env = []string{}
+	}
+	return &ExecTransformer{
+		config:    cfg,
+		remapper:  remapper,
+		logger:    logger.WithFields(app.LogFields{"command": cfg.Command}),
+		env:       env,
+		processes: make(chan struct{}, cfg.MaxProcesses),
+	}
+}
+
+func (t *ExecTransformer) MediaType() string {
+	return t.config.MediaType
+}
+
+func (t *ExecTransformer) FileExtension() string {
+	return t.config.Extension
+}
+
+func (t *ExecTransformer) Transform(ctx context.Context, pages <-chan []byte, w io.Writer) error {
+	select {
+	case t.processes <- struct{}{}:
+		defer func() { <-t.processes }()
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "ExecTransformer: cannot wait for a free process slot")
+	}
+
+	if t.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.config.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, t.config.Command, t.config.Args...)
+	cmd.Dir = t.config.Dir
+	cmd.Env = t.env
+	cmd.Stdout = w
+	stderr := &execStderr{logger: t.logger}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.Wrap(err, "ExecTransformer: cannot open stdin")
+	}
+	if err = cmd.Start(); err != nil {
+		return errors.Wrap(err, "ExecTransformer: cannot start command")
+	}
+
+	writeErr := t.writePages(ctx, pages, stdin)
+	_ = stdin.Close()
+	waitErr := cmd.Wait()
+	stderr.flush()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return errors.Wrap(ErrExecutionTimeout, "ExecTransformer: command is killed")
+	case waitErr != nil:
+		if tail := stderr.tail(); tail != "" {
+			return errors.Wrapf(waitErr, "ExecTransformer: command failed, stderr: %s", tail)
+		}
+		return errors.Wrap(waitErr, "ExecTransformer: command failed")
+	case writeErr != nil:
+		return errors.Wrap(writeErr, "ExecTransformer: cannot write page")
+	}
+	return nil
+}
+
+func (t *ExecTransformer) writePages(ctx context.Context, pages <-chan []byte, stdin io.Writer) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case page, more := <-pages:
+			if !more {
+				return nil
+			}
+			if t.remapper != nil {
+				var err error
+				if page, err = t.remapper.Remap(ctx, page); err != nil {
+					return errors.Wrap(err, "cannot remap page")
+				}
+			}
+			if _, err := stdin.Write(page); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// execStderr logs the stderr of the command line by line and keeps its tail for the error message
+type execStderr struct {
+	mu     sync.Mutex
+	logger app.Logger
+	line   []byte
+	last   []byte
+}
+
+func (s *execStderr) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = append(s.last, p...)
+	if len(s.last) > execStderrTailSize {
+		s.last = s.last[len(s.last)-execStderrTailSize:]
+	}
+	s.line = append(s.line, p...)
+	for {
+		i := bytes.IndexByte(s.line, '\n')
+		if i < 0 {
+			break
+		}
+		s.log(s.line[:i])
+		s.line = s.line[i+1:]
+	}
+	return len(p), nil
+}
+
+func (s *execStderr) log(line []byte) {
+	if line = bytes.TrimSpace(line); len(line) > 0 {
+		s.logger.Warning(string(line))
+	}
+}
+
+func (s *execStderr) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log(s.line)
+	s.line = nil
+}
+
+func (s *execStderr) tail() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return string(bytes.TrimSpace(s.last))
+}
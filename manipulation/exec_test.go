@@ -0,0 +1,110 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/app"
+)
+
+func execPages(pages ...string) <-chan []byte {
+	ch := make(chan []byte, len(pages))
+	for _, page := range pages {
+		ch <- []byte(page)
+	}
+	close(ch)
+	return ch
+}
+
+func TestExecTransformer(t *testing.T) {
+	cases := []struct {
+		cfg      ExecTransformerConfig
+		pages    []string
+		expected string
+	}{
+		{
+			cfg:      ExecTransformerConfig{Command: "cat"},
+			pages:    []string{"first\n", "second\n"},
+			expected: "first\nsecond\n",
+		},
+		{
+			cfg:      ExecTransformerConfig{Command: "tr", Args: []string{"a-z", "A-Z"}},
+			pages:    []string{"abc"},
+			expected: "ABC",
+		},
+		{
+			cfg: ExecTransformerConfig{
+				Command: "sh",
+				Args:    []string{"-c", `printf "%s:%s" "$GREETING" "$(pwd)"`},
+				Env:     map[string]string{"GREETING": "hello"},
+				Dir:     "/",
+			},
+			expected: "hello:/",
+		},
+		{
+			cfg: ExecTransformerConfig{
+				Command: "sh",
+				Args:    []string{"-c", `printf "%s:%s" "$EXEC_TEST_PASSED" "$EXEC_TEST_SECRET"`},
+				PassEnv: []string{"EXEC_TEST_PASSED", "EXEC_TEST_MISSING"},
+			},
+			expected: "passed:",
+		},
+		{
+			cfg: ExecTransformerConfig{
+				Command:    "sh",
+				Args:       []string{"-c", `printf "%s:%s" "$EXEC_TEST_PASSED" "$EXEC_TEST_SECRET"`},
+				InheritEnv: true,
+			},
+			expected: "passed:secret",
+		},
+	}
+	t.Setenv("EXEC_TEST_PASSED", "passed")
+	t.Setenv("EXEC_TEST_SECRET", "secret")
+	for i, c := range cases {
+		meta := fmt.Sprintf("test #%d: %s", i, c.cfg.Command)
+		transformer := NewExecTransformer(nil, app.NewNoopLogger(), c.cfg)
+		w := &bytes.Buffer{}
+		if err := transformer.Transform(context.Background(), execPages(c.pages...), w); err != nil {
+			t.Errorf("%s: unexpected error: %s", meta, err)
+			continue
+		}
+		if w.String() != c.expected {
+			t.Errorf("%s: expected %q, got %q", meta, c.expected, w.String())
+		}
+	}
+}
+
+func TestExecTransformerErrors(t *testing.T) {
+	transformer := NewExecTransformer(nil, app.NewNoopLogger(), ExecTransformerConfig{
+		Command: "sh",
+		Args:    []string{"-c", "echo broken input >&2; exit 3"},
+	})
+	err := transformer.Transform(context.Background(), execPages(), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "broken input") {
+		t.Errorf("expected error containing stderr, got %v", err)
+	}
+
+	transformer = NewExecTransformer(nil, app.NewNoopLogger(), ExecTransformerConfig{
+		Command: "sleep",
+		Args:    []string{"5"},
+		Timeout: 100 * time.Millisecond,
+	})
+	err = transformer.Transform(context.Background(), execPages(), &bytes.Buffer{})
+	if !errors.Is(err, ErrExecutionTimeout) {
+		t.Errorf("expected %s, got %v", ErrExecutionTimeout, err)
+	}
+
+	transformer = NewExecTransformer(nil, app.NewNoopLogger(), ExecTransformerConfig{Command: "cat", MaxProcesses: 1})
+	transformer.processes <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err = transformer.Transform(ctx, execPages(), &bytes.Buffer{}); err == nil {
+		t.Error("expected error when no process slot is available")
+	}
+}
@@ -0,0 +1,221 @@
+package manipulation
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"github.com/zclconf/go-cty/cty/gocty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// RowVariableName is the name of the variable which holds all values of the row,
+// it allows to access columns which names are not valid identifiers e.g. row["first name"]
+const RowVariableName = "row"
+
+// Expression is a safe expression evaluated over a single row, it is based on the HCL expression syntax
+// e.g. `amount * rate`, `concat(first_name, " ", last_name)` or `if(status == "A", "Active", "Inactive")`
+type Expression struct {
+	source string
+	expr   hclsyntax.Expression
+}
+
+func CompileExpression(source string) (*Expression, error) {
+	expr, diags := hclsyntax.ParseExpression([]byte(source), "expression", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "cannot parse expression '%s'", source)
+	}
+	return &Expression{source: source, expr: expr}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression using the given row values as variables
+func (e *Expression) Eval(row map[string]any) (any, error) {
+	vars := make(map[string]cty.Value, len(row)+1)
+	for name, v := range row {
+		value, err := toCtyValue(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot convert value of '%s'", name)
+		}
+		vars[name] = value
+	}
+	rowValue := cty.EmptyObjectVal
+	if len(vars) > 0 {
+		rowValue = cty.ObjectVal(vars)
+	}
+	ctx := &hcl.EvalContext{
+		Variables: make(map[string]cty.Value, len(vars)+1),
+		Functions: expressionFunctions,
+	}
+	for name, value := range vars {
+		if hclsyntax.ValidIdentifier(name) {
+			ctx.Variables[name] = value
+		}
+	}
+	ctx.Variables[RowVariableName] = rowValue
+
+	result, diags := e.expr.Value(ctx)
+	if diags.HasErrors() {
+		if e.referencesNull(ctx) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(diags, "cannot evaluate expression '%s'", e.source)
+	}
+	return fromCtyValue(result)
+}
+
+// referencesNull reports whether any of the values the expression refers to is null,
+// in this case the failed evaluation results in null instead of the error
+// e.g. "amount * rate" is null if the amount is null
+func (e *Expression) referencesNull(ctx *hcl.EvalContext) bool {
+	for _, traversal := range e.expr.Variables() {
+		value, diags := traversal.TraverseAbs(ctx)
+		if !diags.HasErrors() && value.IsNull() {
+			return true
+		}
+	}
+	return false
+}
+
+var expressionFunctions = map[string]function.Function{
+	"if":        ifFunc,
+	"concat":    concatFunc,
+	"round":     roundFunc,
+	"format":    stdlib.FormatFunc,
+	"upper":     stdlib.UpperFunc,
+	"lower":     stdlib.LowerFunc,
+	"title":     stdlib.TitleFunc,
+	"trimspace": stdlib.TrimSpaceFunc,
+	"substr":    stdlib.SubstrFunc,
+	"replace":   stdlib.ReplaceFunc,
+	"strlen":    stdlib.StrlenFunc,
+	"coalesce":  stdlib.CoalesceFunc,
+	"abs":       stdlib.AbsoluteFunc,
+	"ceil":      stdlib.CeilFunc,
+	"floor":     stdlib.FloorFunc,
+	"min":       stdlib.MinFunc,
+	"max":       stdlib.MaxFunc,
+}
+
+var (
+	ifFunc = function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "condition", Type: cty.Bool},
+			{Name: "then", Type: cty.DynamicPseudoType, AllowNull: true},
+			{Name: "else", Type: cty.DynamicPseudoType, AllowNull: true},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if args[0].True() {
+				return args[1], nil
+			}
+			return args[2], nil
+		},
+	})
+	concatFunc = function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name:      "values",
+			Type:      cty.DynamicPseudoType,
+			AllowNull: true,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			sb := strings.Builder{}
+			for _, arg := range args {
+				if arg.IsNull() {
+					continue
+				}
+				str, err := convert.Convert(arg, cty.String)
+				if err != nil {
+					return cty.NilVal, err
+				}
+				sb.WriteString(str.AsString())
+			}
+			return cty.StringVal(sb.String()), nil
+		},
+	})
+	roundFunc = function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "num", Type: cty.Number},
+		},
+		VarParam: &function.Parameter{
+			Name: "places",
+			Type: cty.Number,
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			num, _ := args[0].AsBigFloat().Float64()
+			places := 0
+			if len(args) > 1 {
+				if err := gocty.FromCtyValue(args[1], &places); err != nil {
+					return cty.NilVal, err
+				}
+			}
+			pow := math.Pow(10, float64(places))
+			return cty.NumberFloatVal(math.Round(num*pow) / pow), nil
+		},
+	})
+)
+
+func toCtyValue(v any) (cty.Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case string:
+		return cty.StringVal(val), nil
+	case bool:
+		return cty.BoolVal(val), nil
+	case float64:
+		return cty.NumberFloatVal(val), nil
+	case int:
+		return cty.NumberIntVal(int64(val)), nil
+	case int64:
+		return cty.NumberIntVal(val), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	value := new(ctyjson.SimpleJSONValue)
+	if err = value.UnmarshalJSON(data); err != nil {
+		return cty.NilVal, err
+	}
+	return value.Value, nil
+}
+
+func fromCtyValue(v cty.Value) (any, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	if !v.IsWhollyKnown() {
+		return nil, errors.New("value is unknown")
+	}
+	switch v.Type() {
+	case cty.String:
+		return v.AsString(), nil
+	case cty.Bool:
+		return v.True(), nil
+	case cty.Number:
+		f, _ := v.AsBigFloat().Float64()
+		return f, nil
+	}
+	data, err := ctyjson.SimpleJSONValue{Value: v}.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
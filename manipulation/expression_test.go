@@ -0,0 +1,91 @@
+package manipulation
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type expressionTest struct {
+	expression string
+	row        map[string]any
+	out        any
+}
+
+var expressionTests = []expressionTest{
+	{
+		expression: "amount * rate",
+		row:        map[string]any{"amount": 10.0, "rate": "1.5"},
+		out:        15.0,
+	},
+	{
+		expression: `concat(first_name, " ", last_name)`,
+		row:        map[string]any{"first_name": "Alan", "last_name": "Turing"},
+		out:        "Alan Turing",
+	},
+	{
+		expression: `if(status == "A", "Active", "Inactive")`,
+		row:        map[string]any{"status": "B"},
+		out:        "Inactive",
+	},
+	{
+		expression: `upper(row["first name"])`,
+		row:        map[string]any{"first name": "linus"},
+		out:        "LINUS",
+	},
+	{
+		expression: "round(amount / 3, 2)",
+		row:        map[string]any{"amount": 10.0},
+		out:        3.33,
+	},
+	{
+		expression: `coalesce(nickname, "anonymous")`,
+		row:        map[string]any{"nickname": nil},
+		out:        "anonymous",
+	},
+	{
+		expression: "amount * rate",
+		row:        map[string]any{"amount": nil, "rate": 1.5},
+		out:        nil,
+	},
+	{
+		expression: `concat(upper(row["first name"]), " ", last_name)`,
+		row:        map[string]any{"first name": nil, "last_name": "Turing"},
+		out:        nil,
+	},
+}
+
+func TestExpressionEval(t *testing.T) {
+	for i, tt := range expressionTests {
+		meta := fmt.Sprintf("test #%d: Expression(%q).Eval(%+v),", i, tt.expression, tt.row)
+		expr, err := CompileExpression(tt.expression)
+		if err != nil {
+			t.Errorf("%s unexpected compile error: %s", meta, err)
+			continue
+		}
+		out, err := expr.Eval(tt.row)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("%s expected to return %#v, got %#v", meta, tt.out, out)
+		}
+	}
+}
+
+func TestExpressionEvalError(t *testing.T) {
+	expr, err := CompileExpression("amount * rate")
+	if err != nil {
+		t.Fatalf("unexpected compile error: %s", err)
+	}
+	if _, err = expr.Eval(map[string]any{"amount": "ten", "rate": 1.5}); err == nil {
+		t.Error(`Expression("amount * rate").Eval() expected to return error for a non-numeric amount`)
+	}
+}
+
+func TestCompileExpressionSyntaxError(t *testing.T) {
+	if _, err := CompileExpression("amount *"); err == nil {
+		t.Error(`CompileExpression("amount *") expected to return error`)
+	}
+}
@@ -0,0 +1,164 @@
+package manipulation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultJavaScriptFunction         = "remap"
+	DefaultJavaScriptTimeout          = 5 * time.Second
+	DefaultJavaScriptMaxCallStackSize = 1024
+)
+
+type JavaScriptRemapperConfig struct {
+	// Script is the source code which defines the remapping function
+	Script string
+	// Function is the name of the function which is called as function(input, params, request)
+	Function string
+	// Timeout limits the execution time of a single call
+	Timeout time.Duration
+	// MaxCallStackSize limits the depth of the call stack
+	MaxCallStackSize int
+}
+
+// JavaScriptRemapper transforms JSON by means of a JavaScript function executed by the embedded engine
+// https://github.com/dop251/goja
+// The function receives the parsed input, the route path parameters and the request data
+// as an object with the params, query, headers and now properties, see RequestData,
+// and returns a value which is encoded as JSON.
+// Scripts have access to the ECMAScript built-ins only, there is no filesystem, network or module access.
+// The memory of a call is not limited since the engine does not account memory per virtual machine,
+// the timeout is the protection from the runaway scripts.
+// Virtual machines are pooled and reused, so scripts must not rely on the global state between calls.
+type JavaScriptRemapper struct {
+	cfg     JavaScriptRemapperConfig
+	program *goja.Program
+	pool    sync.Pool
+}
+
+type javaScriptVM struct {
+	runtime   *goja.Runtime
+	function  goja.Callable
+	parse     goja.Callable
+	stringify goja.Callable
+}
+
+func NewJavaScriptRemapper(cfg JavaScriptRemapperConfig) (*JavaScriptRemapper, error) {
+	if cfg.Function == "" {
+		cfg.Function = DefaultJavaScriptFunction
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultJavaScriptTimeout
+	}
+	if cfg.MaxCallStackSize <= 0 {
+		cfg.MaxCallStackSize = DefaultJavaScriptMaxCallStackSize
+	}
+	program, err := goja.Compile("remapper.js", cfg.Script, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "JavaScriptRemapper: cannot compile script")
+	}
+	r := &JavaScriptRemapper{cfg: cfg, program: program}
+	// the first VM is created eagerly, so that a script which does not define the function is reported on startup
+	vm, err := r.newVM(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	r.pool.Put(vm)
+	return r, nil
+}
+
+func (r *JavaScriptRemapper) Remap(ctx context.Context, in []byte) ([]byte, error) {
+	vm, ok := r.pool.Get().(*javaScriptVM)
+	if !ok {
+		var err error
+		if vm, err = r.newVM(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	variables := RequestFromContext(ctx).Variables()
+	params := vm.runtime.ToValue(variables["params"])
+	request := vm.runtime.ToValue(variables)
+
+	var out goja.Value
+	err := r.guard(ctx, vm.runtime, func() error {
+		input, err := vm.parse(goja.Undefined(), vm.runtime.ToValue(string(in)))
+		if err != nil {
+			return errors.Wrap(err, "JavaScriptRemapper: cannot parse input")
+		}
+		result, err := vm.function(goja.Undefined(), input, params, request)
+		if err != nil {
+			return errors.Wrap(err, "JavaScriptRemapper: cannot execute script")
+		}
+		if out, err = vm.stringify(goja.Undefined(), result); err != nil {
+			return errors.Wrap(err, "JavaScriptRemapper: cannot encode output")
+		}
+		return nil
+	})
+	if err != nil {
+		// the VM state is unknown after a failure, so it is not returned to the pool
+		return nil, err
+	}
+	r.pool.Put(vm)
+
+	if goja.IsUndefined(out) {
+		return []byte("null"), nil
+	}
+	return []byte(out.String()), nil
+}
+
+func (r *JavaScriptRemapper) newVM(ctx context.Context) (*javaScriptVM, error) {
+	rt := goja.New()
+	rt.SetMaxCallStackSize(r.cfg.MaxCallStackSize)
+	err := r.guard(ctx, rt, func() error {
+		_, err := rt.RunProgram(r.program)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "JavaScriptRemapper: cannot run script")
+	}
+	vm := &javaScriptVM{runtime: rt}
+	var ok bool
+	if vm.function, ok = goja.AssertFunction(rt.Get(r.cfg.Function)); !ok {
+		return nil, errors.Errorf("JavaScriptRemapper: script does not define function '%s'", r.cfg.Function)
+	}
+	json := rt.Get("JSON").ToObject(rt)
+	vm.parse, _ = goja.AssertFunction(json.Get("parse"))
+	vm.stringify, _ = goja.AssertFunction(json.Get("stringify"))
+	return vm, nil
+}
+
+// guard executes fn interrupting the VM if the context is done or the timeout is exceeded,
+// the reason of the interruption is returned as the error cause
+func (r *JavaScriptRemapper) guard(ctx context.Context, rt *goja.Runtime, fn func() error) error {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		timer := time.NewTimer(r.cfg.Timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			rt.Interrupt(ctx.Err())
+		case <-timer.C:
+			rt.Interrupt(ErrExecutionTimeout)
+		}
+	}()
+	err := fn()
+	close(done)
+	<-stopped
+	rt.ClearInterrupt()
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if reason, ok := interrupted.Value().(error); ok {
+			return errors.Wrap(reason, "JavaScriptRemapper: script is interrupted")
+		}
+	}
+	return err
+}
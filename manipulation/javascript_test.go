@@ -0,0 +1,70 @@
+package manipulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/route"
+)
+
+func TestJavaScriptRemapper(t *testing.T) {
+	remapper, err := NewJavaScriptRemapper(JavaScriptRemapperConfig{
+		Script: `function remap(input, params) {
+  return {
+    id: params.id,
+    titles: input.data.filter(function (item) { return item.price > 10 }).map(function (item) { return item.title })
+  }
+}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx := route.ContextWithParameters(context.Background(), route.NamedPathParameters{"id": "42"})
+	for i := 0; i < 3; i++ {
+		out, err := remapper.Remap(ctx, []byte(`{"data":[{"title":"a","price":5},{"title":"b","price":15}]}`))
+		if err != nil {
+			t.Fatalf("test #%d: unexpected error: %s", i, err)
+		}
+		expected := `{"id":"42","titles":["b"]}`
+		if string(out) != expected {
+			t.Errorf("test #%d: expected %s, got %s", i, expected, out)
+		}
+	}
+}
+
+func TestJavaScriptRemapperErrors(t *testing.T) {
+	if _, err := NewJavaScriptRemapper(JavaScriptRemapperConfig{Script: `function remap(input {`}); err == nil {
+		t.Error("NewJavaScriptRemapper expected to return error for invalid script")
+	}
+	if _, err := NewJavaScriptRemapper(JavaScriptRemapperConfig{Script: `function other() {}`}); err == nil {
+		t.Error("NewJavaScriptRemapper expected to return error if the function is not defined")
+	}
+
+	remapper, err := NewJavaScriptRemapper(JavaScriptRemapperConfig{
+		Script:  `function remap(input) { for (;;) {} }`,
+		Timeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = remapper.Remap(context.Background(), []byte(`{}`)); errors.Cause(err) != ErrExecutionTimeout {
+		t.Errorf("expected %s, got %v", ErrExecutionTimeout, err)
+	}
+
+	remapper, err = NewJavaScriptRemapper(JavaScriptRemapperConfig{
+		Script: `function remap(input) { return typeof require + typeof process + typeof fetch }`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out, err := remapper.Remap(context.Background(), []byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(out) != `"undefinedundefinedundefined"` {
+		t.Errorf("expected no host objects to be available, got %s", out)
+	}
+}
@@ -0,0 +1,100 @@
+package manipulation
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/itchyny/gojq"
+	"github.com/pkg/errors"
+)
+
+// jq variables which hold the request data, see RequestData
+const (
+	JQParamsVariable  = "$params"
+	JQQueryVariable   = "$query"
+	JQHeadersVariable = "$headers"
+	JQNowVariable     = "$now"
+)
+
+var jqRequestVariables = []string{JQParamsVariable, JQQueryVariable, JQHeadersVariable, JQNowVariable}
+
+// JQRemapper transforms JSON by means of a jq program https://jqlang.github.io/jq/manual/
+// Route path parameters are available as variables e.g. $id for the route "/accounts/:id"
+// and all together as the $params object, the query string parameters and the request headers
+// are available as the $query and $headers objects and the request time as the $now string.
+// Other variables are not declared, so the program which refers to them is rejected by the compiler.
+// If the program produces several results they are collected into an array.
+type JQRemapper struct {
+	code      *gojq.Code
+	variables []string
+}
+
+// NewJQRemapper compiles the program, pathParameters are the names of the route path parameters
+// which are declared as variables
+func NewJQRemapper(program string, pathParameters ...string) (*JQRemapper, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, errors.Wrap(err, "JQRemapper: cannot parse program")
+	}
+	variables := append([]string(nil), jqRequestVariables...)
+	seen := make(map[string]bool, len(variables)+len(pathParameters))
+	for _, name := range variables {
+		seen[name] = true
+	}
+	for _, name := range pathParameters {
+		if name = "$" + name; !seen[name] {
+			seen[name] = true
+			variables = append(variables, name)
+		}
+	}
+	code, err := gojq.Compile(query, gojq.WithVariables(variables))
+	if err != nil {
+		return nil, errors.Wrap(err, "JQRemapper: cannot compile program")
+	}
+	return &JQRemapper{code: code, variables: variables}, nil
+}
+
+func (r *JQRemapper) Remap(ctx context.Context, in []byte) ([]byte, error) {
+	var input any
+	if err := json.Unmarshal(in, &input); err != nil {
+		return nil, errors.Wrap(err, "JQRemapper: cannot parse input")
+	}
+
+	request := RequestFromContext(ctx)
+	requestVariables := request.Variables()
+	values := make([]any, len(r.variables))
+	for i, name := range r.variables {
+		if i < len(jqRequestVariables) {
+			values[i] = requestVariables[name[1:]]
+		} else if value, ok := request.Params[name[1:]]; ok {
+			values[i] = value
+		}
+	}
+
+	var results []any
+	iter := r.code.RunWithContext(ctx, input, values...)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return nil, errors.Wrap(err, "JQRemapper: cannot execute program")
+		}
+		results = append(results, v)
+	}
+
+	var output any
+	switch len(results) {
+	case 0:
+	case 1:
+		output = results[0]
+	default:
+		output = results
+	}
+	out, err := json.Marshal(output)
+	if err != nil {
+		return nil, errors.Wrap(err, "JQRemapper: cannot encode output")
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package manipulation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/velmie/alternea/route"
+)
+
+func TestJQRemapper(t *testing.T) {
+	remapper, err := NewJQRemapper(`{id: $id, titles: [.data[] | select(.price > 10) | .title]}`, "id")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ctx := route.ContextWithParameters(context.Background(), route.NamedPathParameters{"id": "42"})
+	out, err := remapper.Remap(ctx, []byte(`{"data":[{"title":"a","price":5},{"title":"b","price":15}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `{"id":"42","titles":["b"]}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
+
+func TestJQRemapperCompileError(t *testing.T) {
+	if _, err := NewJQRemapper(`.data[] |`); err == nil {
+		t.Error("NewJQRemapper expected to return error for invalid program")
+	}
+	if _, err := NewJQRemapper(`undefined_function(1)`); err == nil {
+		t.Error("NewJQRemapper expected to return error for undefined function")
+	}
+}
+
+func TestJQRemapperVariables(t *testing.T) {
+	remapper, err := NewJQRemapper(`{text: "costs $price", id: $id, items: [.[] as $item | $item]}`, "id", "params")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := append(append([]string(nil), jqRequestVariables...), "$id")
+	if !reflect.DeepEqual(remapper.variables, expected) {
+		t.Errorf("expected variables %v, got %v", expected, remapper.variables)
+	}
+
+	for i, program := range []string{`$acount`, `{$page}`, `$id`} {
+		if _, err := NewJQRemapper(program, "account"); err == nil {
+			t.Errorf("test #%d: NewJQRemapper(%q) expected to return error for undefined variable", i, program)
+		}
+	}
+}
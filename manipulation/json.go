@@ -1,7 +1,12 @@
 package manipulation
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"strings"
+	"text/template"
 
 	"github.com/pkg/errors"
 	"github.com/qntfy/kazaam"
@@ -35,11 +40,11 @@ func NewJSONTablifier(
 	return &JSONTablifier{columnsRemapper, logger, config}
 }
 
-func (t *JSONTablifier) Table(in []byte) (*dframe.Table, error) {
+func (t *JSONTablifier) Table(ctx context.Context, in []byte) (*dframe.Table, error) {
 	if t.logger.Level() >= app.DebugLevel {
 		t.logger.Debugf("JSONTablifier: input data:\n%s\n", in)
 	}
-	out, err := t.columnsRemapper.Remap(in)
+	out, err := t.columnsRemapper.Remap(ctx, in)
 	if err != nil {
 		return nil, errors.Wrap(err, "JSONTablifier: cannot remap given input")
 	}
@@ -114,10 +119,206 @@ func NewKazaamRemapper(k *kazaam.Kazaam) *KazaamRemapper {
 	return &KazaamRemapper{k: k}
 }
 
-func (r *KazaamRemapper) Remap(in []byte) ([]byte, error) {
+func (r *KazaamRemapper) Remap(_ context.Context, in []byte) ([]byte, error) {
 	return r.k.Transform(in)
 }
 
+// KazaamTemplateRemapper renders the string values of the kazaam specification which contain "{{"
+// as text/templates per request with the RequestData as the template data
+// e.g. {"operation": "default", "spec": {"from": "{{ .Query.from }}"}}
+// rendered values are always strings, missing keys result in empty strings
+type KazaamTemplateRemapper struct {
+	spec any
+}
+
+func NewKazaamTemplateRemapper(spec string) (*KazaamTemplateRemapper, error) {
+	var value any
+	if err := json.Unmarshal([]byte(spec), &value); err != nil {
+		return nil, errors.Wrap(err, "KazaamTemplateRemapper: cannot parse specification")
+	}
+	value, err := parseSpecTemplates(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "KazaamTemplateRemapper: cannot parse specification template")
+	}
+	r := &KazaamTemplateRemapper{spec: value}
+	// the specification is checked with empty request data in order to report errors on startup
+	if _, err = r.kazaam(&RequestData{}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *KazaamTemplateRemapper) Remap(ctx context.Context, in []byte) ([]byte, error) {
+	k, err := r.kazaam(RequestFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return k.Transform(in)
+}
+
+func (r *KazaamTemplateRemapper) kazaam(data *RequestData) (*kazaam.Kazaam, error) {
+	spec, err := renderSpecTemplates(r.spec, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "KazaamTemplateRemapper: cannot execute specification template")
+	}
+	specBytes, _ := json.Marshal(spec)
+	k, err := kazaam.NewKazaam(string(specBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "KazaamTemplateRemapper: cannot create kazaam instance")
+	}
+	return k, nil
+}
+
+// parseSpecTemplates replaces the string values which contain template actions with parsed templates
+func parseSpecTemplates(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		return template.New("spec").Option("missingkey=zero").Parse(v)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			parsed, err := parseSpecTemplates(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "'%s'", key)
+			}
+			result[key] = parsed
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			parsed, err := parseSpecTemplates(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[%d]", i)
+			}
+			result[i] = parsed
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func renderSpecTemplates(value any, data *RequestData) (any, error) {
+	switch v := value.(type) {
+	case *template.Template:
+		out := new(strings.Builder)
+		if err := v.Execute(out, data); err != nil {
+			return nil, err
+		}
+		return out.String(), nil
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			rendered, err := renderSpecTemplates(item, data)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = rendered
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			rendered, err := renderSpecTemplates(item, data)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = rendered
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// JSONTransformer writes the pages as a single JSON document: a single page is written as is,
+// several pages are merged into one array, the elements of the pages which are arrays are added
+// to the array one by one and other pages are added as elements
+type JSONTransformer struct {
+	remapper Remapper
+}
+
+// NewJSONTransformer creates the transformer, the remapper is optional
+func NewJSONTransformer(remapper Remapper) *JSONTransformer {
+	return &JSONTransformer{remapper: remapper}
+}
+
+func (t *JSONTransformer) MediaType() string {
+	return "application/json"
+}
+
+func (t *JSONTransformer) FileExtension() string {
+	return ".json"
+}
+
+func (t *JSONTransformer) Transform(ctx context.Context, pages <-chan []byte, w io.Writer) error {
+	// the first page is held until it is known whether it is the only page
+	var first []byte
+	count, empty := 0, true
+	for page := range pages {
+		if t.remapper != nil {
+			var err error
+			if page, err = t.remapper.Remap(ctx, page); err != nil {
+				return errors.Wrap(err, "JSONTransformer: cannot remap page")
+			}
+		}
+		if !json.Valid(page) {
+			return errors.Wrap(ErrUnsupportedDataType, "JSONTransformer: page is not valid JSON")
+		}
+		page = bytes.TrimSpace(page)
+		count++
+		switch count {
+		case 1:
+			first = page
+			continue
+		case 2:
+			if _, err := w.Write([]byte("[")); err != nil {
+				return errors.Wrap(err, "JSONTransformer: cannot write page")
+			}
+			if err := writeJSONElements(w, first, &empty); err != nil {
+				return err
+			}
+			first = nil
+		}
+		if err := writeJSONElements(w, page, &empty); err != nil {
+			return err
+		}
+	}
+	var tail []byte
+	switch count {
+	case 0:
+		return nil
+	case 1:
+		tail = append(first, '\n')
+	default:
+		tail = []byte("]\n")
+	}
+	if _, err := w.Write(tail); err != nil {
+		return errors.Wrap(err, "JSONTransformer: cannot write page")
+	}
+	return nil
+}
+
+// writeJSONElements writes the elements of the array or the value itself as the elements of the merged array
+func writeJSONElements(w io.Writer, page []byte, empty *bool) error {
+	if page[0] == '[' {
+		page = bytes.TrimSpace(page[1 : len(page)-1])
+		if len(page) == 0 {
+			return nil
+		}
+	}
+	if !*empty {
+		page = append([]byte(","), page...)
+	}
+	*empty = false
+	if _, err := w.Write(page); err != nil {
+		return errors.Wrap(err, "JSONTransformer: cannot write page")
+	}
+	return nil
+}
+
 type NoOpRemapper struct {
 }
 
@@ -125,6 +326,6 @@ func NewNoOpRemapper() *NoOpRemapper {
 	return &NoOpRemapper{}
 }
 
-func (n NoOpRemapper) Remap(in []byte) ([]byte, error) {
+func (n NoOpRemapper) Remap(_ context.Context, in []byte) ([]byte, error) {
 	return in, nil
 }
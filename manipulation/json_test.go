@@ -0,0 +1,46 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestJSONTransformer(t *testing.T) {
+	cases := []struct {
+		pages    []string
+		expected string
+	}{
+		{nil, ``},
+		{[]string{`{"id": 1}`}, "{\"id\": 1}\n"},
+		{[]string{` [1, 2] `}, "[1, 2]\n"},
+		{[]string{`[1, 2]`, `[3]`}, "[1, 2,3]\n"},
+		{[]string{`[1, 2]`, `[]`, `[ ]`, `[3]`}, "[1, 2,3]\n"},
+		{[]string{`[]`, `[1]`}, "[1]\n"},
+		{[]string{`[]`, `[]`}, "[]\n"},
+		{[]string{`{"id": 1}`, `{"id": 2}`}, "[{\"id\": 1},{\"id\": 2}]\n"},
+		{[]string{`[{"id": 1}]`, `{"id": 2}`, `"three"`}, "[{\"id\": 1},{\"id\": 2},\"three\"]\n"},
+		{[]string{`[[1], [2]]`, `[[3]]`}, "[[1], [2],[3]]\n"},
+	}
+	for i, c := range cases {
+		meta := fmt.Sprintf("test #%d: %v", i, c.pages)
+		w := &bytes.Buffer{}
+		if err := NewJSONTransformer(nil).Transform(context.Background(), execPages(c.pages...), w); err != nil {
+			t.Errorf("%s: unexpected error: %s", meta, err)
+			continue
+		}
+		if w.String() != c.expected {
+			t.Errorf("%s: expected %q, got %q", meta, c.expected, w.String())
+		}
+		if w.Len() > 0 && !json.Valid(w.Bytes()) {
+			t.Errorf("%s: expected valid JSON, got %s", meta, w)
+		}
+	}
+
+	err := NewJSONTransformer(nil).Transform(context.Background(), execPages(`[1]`, `{broken`), &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected error for invalid page")
+	}
+}
@@ -0,0 +1,74 @@
+package manipulation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/blues/jsonata-go"
+	"github.com/pkg/errors"
+)
+
+// JSONata variables which hold the request data, see RequestData
+const (
+	JSONataParamsVariable  = "params"
+	JSONataQueryVariable   = "query"
+	JSONataHeadersVariable = "headers"
+)
+
+// JSONataRemapper transforms JSON by means of a JSONata expression https://docs.jsonata.org/
+// evaluated by https://github.com/blues/jsonata-go
+// Route path parameters are available as the $params object e.g. $params.id for the route "/accounts/:id",
+// the query string parameters and the request headers are available as the $query and $headers objects.
+// The request time is not bound since the $now() function of the standard library is available.
+type JSONataRemapper struct {
+	source string
+	// compiled expressions are pooled since the variables are bound to the expression itself
+	pool sync.Pool
+}
+
+func NewJSONataRemapper(expression string) (*JSONataRemapper, error) {
+	e, err := jsonata.Compile(expression)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSONataRemapper: cannot compile expression")
+	}
+	r := &JSONataRemapper{source: expression}
+	r.pool.Put(e)
+	return r, nil
+}
+
+func (r *JSONataRemapper) Remap(ctx context.Context, in []byte) ([]byte, error) {
+	var input any
+	if err := json.Unmarshal(in, &input); err != nil {
+		return nil, errors.Wrap(err, "JSONataRemapper: cannot parse input")
+	}
+
+	e, ok := r.pool.Get().(*jsonata.Expr)
+	if !ok {
+		var err error
+		if e, err = jsonata.Compile(r.source); err != nil {
+			return nil, errors.Wrap(err, "JSONataRemapper: cannot compile expression")
+		}
+	}
+	defer r.pool.Put(e)
+
+	variables := RequestFromContext(ctx).Variables()
+	err := e.RegisterVars(map[string]any{
+		JSONataParamsVariable:  variables["params"],
+		JSONataQueryVariable:   variables["query"],
+		JSONataHeadersVariable: variables["headers"],
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "JSONataRemapper: cannot bind request data")
+	}
+
+	output, err := e.Eval(input)
+	if err != nil && err != jsonata.ErrUndefined {
+		return nil, errors.Wrap(err, "JSONataRemapper: cannot evaluate expression")
+	}
+	out, err := json.Marshal(output)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSONataRemapper: cannot encode output")
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package manipulation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/velmie/alternea/route"
+)
+
+func TestJSONataRemapper(t *testing.T) {
+	cases := []struct {
+		expression string
+		expected   string
+	}{
+		{
+			`{"id": $params.id, "titles": data[price > 10].title[], "count": $count(data)}`,
+			`{"count":2,"id":"42","titles":["b"]}`,
+		},
+		// the route parameter named like a function does not shadow it
+		{`$count(data) & "/" & $params.count`, `"2/7"`},
+		{`data[$contains(title, /^B$/i)].$formatNumber(price, "#,##0.00")`, `"15.00"`},
+		{`$map(data, function($v, $i) { $i & ":" & $uppercase($v.title) })`, `["0:A","1:B"]`},
+		{`data[price > 100]`, `null`},
+	}
+	ctx := route.ContextWithParameters(context.Background(), route.NamedPathParameters{"id": "42", "count": "7"})
+	for i, c := range cases {
+		meta := fmt.Sprintf("test #%d: %s", i, c.expression)
+		remapper, err := NewJSONataRemapper(c.expression)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", meta, err)
+			continue
+		}
+		out, err := remapper.Remap(ctx, []byte(`{"data":[{"title":"a","price":5},{"title":"b","price":15}]}`))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", meta, err)
+			continue
+		}
+		if string(out) != c.expected {
+			t.Errorf("%s: expected %s, got %s", meta, c.expected, out)
+		}
+	}
+}
+
+func TestJSONataRemapperConcurrent(t *testing.T) {
+	remapper, err := NewJSONataRemapper(`$params.id`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			ctx := route.ContextWithParameters(context.Background(), route.NamedPathParameters{"id": id})
+			out, err := remapper.Remap(ctx, []byte(`{}`))
+			if err != nil || string(out) != `"`+id+`"` {
+				t.Errorf("expected %q, got %s, %v", id, out, err)
+			}
+		}(fmt.Sprint(i))
+	}
+	wg.Wait()
+}
+
+func TestJSONataRemapperCompileError(t *testing.T) {
+	if _, err := NewJSONataRemapper(`data[price >`); err == nil {
+		t.Error("NewJSONataRemapper expected to return error for invalid expression")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"html/template"
 	"io"
+	"time"
 
 	pdf "github.com/SebastiaanKlippert/go-wkhtmltopdf"
 	"github.com/pkg/errors"
@@ -13,13 +14,34 @@ import (
 
 type PDFTransformer struct {
 	remapper     Remapper
+	tablifier    Tablifier
 	pageTemplate *template.Template
 }
 
+// TemplateTable is the tabular form of the page data available in the template
+type TemplateTable struct {
+	Header []string
+	Rows   [][]string
+}
+
 func NewPDFTransformer(remapper Remapper, pageTemplate *template.Template) *PDFTransformer {
 	return &PDFTransformer{remapper: remapper, pageTemplate: pageTemplate}
 }
 
+// SetTablifier specifies the tablifier which is used in order to make
+// the page data available in the template as a table under the .Table property
+func (t *PDFTransformer) SetTablifier(tablifier Tablifier) {
+	t.tablifier = tablifier
+}
+
+func (t *PDFTransformer) MediaType() string {
+	return "application/pdf"
+}
+
+func (t *PDFTransformer) FileExtension() string {
+	return ".pdf"
+}
+
 func (t *PDFTransformer) Transform(ctx context.Context, pages <-chan []byte, w io.Writer) error {
 	pipeReader, pipeWriter := io.Pipe()
 
@@ -39,8 +61,14 @@ func (t *PDFTransformer) Transform(ctx context.Context, pages <-chan []byte, w i
 
 	var data []byte
 	type templateData struct {
-		Data any
+		Data    any
+		Table   *TemplateTable
+		Params  map[string]string
+		Query   map[string]string
+		Headers map[string]string
+		Now     time.Time
 	}
+	request := RequestFromContext(ctx)
 LOOP:
 	for {
 		select {
@@ -50,15 +78,27 @@ LOOP:
 			if !more {
 				break LOOP
 			}
-			data, err = t.remapper.Remap(page)
+			data, err = t.remapper.Remap(ctx, page)
 			if err != nil {
 				if err != nil {
 					return errors.Wrap(err, "PDFTransformer: cannot remap given input")
 				}
 			}
-			err = t.pageTemplate.Execute(pipeWriter, &templateData{
-				Data: gjson.ParseBytes(data).Value(),
-			})
+			tplData := &templateData{
+				Data:    gjson.ParseBytes(data).Value(),
+				Params:  request.Params,
+				Query:   request.Query,
+				Headers: request.Headers,
+				Now:     request.Now,
+			}
+			if t.tablifier != nil {
+				table, err := t.tablifier.Table(ctx, page)
+				if err != nil {
+					return errors.Wrap(err, "PDFTransformer: cannot get table")
+				}
+				tplData.Table = &TemplateTable{Header: table.Header(), Rows: table.StringSlices()}
+			}
+			err = t.pageTemplate.Execute(pipeWriter, tplData)
 			if err != nil {
 				return errors.Wrap(err, "PDFTransformer: cannot execute page template")
 			}
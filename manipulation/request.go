@@ -0,0 +1,73 @@
+package manipulation
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/velmie/alternea/route"
+)
+
+// RequestData holds the data of the client request which is available to remappers and templates
+type RequestData struct {
+	Params  map[string]string // Route path parameters
+	Query   map[string]string // Query string parameters, the first value is used for repeated parameters
+	Headers map[string]string // Request headers with canonical names e.g. "X-Request-Id", multiple values are joined by ", "
+	Now     time.Time         // Time when the request was received
+}
+
+type requestContextKey struct{}
+
+// ContextWithRequest returns a copy of the context which holds the data of the request,
+// the route path parameters are taken from the request context
+func ContextWithRequest(ctx context.Context, r *http.Request) context.Context {
+	data := &RequestData{
+		Params:  route.ParametersFromContext(ctx),
+		Query:   make(map[string]string),
+		Headers: make(map[string]string, len(r.Header)),
+		Now:     time.Now(),
+	}
+	for name, values := range r.URL.Query() {
+		if len(values) > 0 {
+			data.Query[name] = values[0]
+		}
+	}
+	for name := range r.Header {
+		data.Headers[http.CanonicalHeaderKey(name)] = strings.Join(r.Header.Values(name), ", ")
+	}
+	return context.WithValue(ctx, requestContextKey{}, data)
+}
+
+// RequestFromContext returns the data of the request, if the context does not hold it
+// then only the route path parameters and the current time are set
+func RequestFromContext(ctx context.Context) *RequestData {
+	if data, ok := ctx.Value(requestContextKey{}).(*RequestData); ok {
+		return data
+	}
+	return &RequestData{
+		Params:  route.ParametersFromContext(ctx),
+		Query:   map[string]string{},
+		Headers: map[string]string{},
+		Now:     time.Now(),
+	}
+}
+
+// Variables returns the request data in the form of JSON values: objects "params", "query", "headers"
+// and the string "now" formatted as RFC 3339
+func (d *RequestData) Variables() map[string]any {
+	return map[string]any{
+		"params":  stringMap(d.Params),
+		"query":   stringMap(d.Query),
+		"headers": stringMap(d.Headers),
+		"now":     d.Now.Format(time.RFC3339),
+	}
+}
+
+func stringMap(m map[string]string) map[string]any {
+	result := make(map[string]any, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package manipulation
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/velmie/alternea/route"
+)
+
+func TestContextWithRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/accounts/42?from=2024-01-01&from=2024-02-01&to=2024-03-01", nil)
+	r.Header.Add("x-request-id", "abc")
+	r.Header.Add("Accept", "text/csv")
+	r.Header.Add("Accept", "application/json")
+	ctx := route.ContextWithParameters(context.Background(), route.NamedPathParameters{"id": "42"})
+
+	data := RequestFromContext(ContextWithRequest(ctx, r))
+	if data.Params["id"] != "42" {
+		t.Errorf("expected param id '42', got '%s'", data.Params["id"])
+	}
+	if data.Query["from"] != "2024-01-01" || data.Query["to"] != "2024-03-01" {
+		t.Errorf("unexpected query %v", data.Query)
+	}
+	if data.Headers["X-Request-Id"] != "abc" || data.Headers["Accept"] != "text/csv, application/json" {
+		t.Errorf("unexpected headers %v", data.Headers)
+	}
+	if data.Now.IsZero() {
+		t.Error("expected the request time to be set")
+	}
+}
+
+func TestRequestDataInRemappers(t *testing.T) {
+	r := httptest.NewRequest("GET", "/accounts/42?from=2024-01-01", nil)
+	r.Header.Set("X-Tenant", "acme")
+	ctx := ContextWithRequest(
+		route.ContextWithParameters(context.Background(), route.NamedPathParameters{"id": "42"}),
+		r,
+	)
+	in := []byte(`{"items":[]}`)
+
+	jq, err := NewJQRemapper(`{id: $params.id, from: $query.from, tenant: $headers["X-Tenant"], dated: ($now | length > 0)}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	jsonata, err := NewJSONataRemapper(`{"id": $params.id, "from": $query.from, "tenant": $lookup($headers, "X-Tenant")}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	javaScript, err := NewJavaScriptRemapper(JavaScriptRemapperConfig{
+		Script: `function remap(input, params, request) {
+			return {id: params.id, from: request.query.from, tenant: request.headers["X-Tenant"]}
+		}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	kazaam, err := NewKazaamTemplateRemapper(
+		`[{"operation": "default", "spec": {"range": "{{ .Params.id }}:{{ .Query.from }}:{{ .Query.to }}"}}]`,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		remapper Remapper
+		expected string
+	}{
+		{jq, `{"dated":true,"from":"2024-01-01","id":"42","tenant":"acme"}`},
+		{jsonata, `{"from":"2024-01-01","id":"42","tenant":"acme"}`},
+		{javaScript, `{"id":"42","from":"2024-01-01","tenant":"acme"}`},
+		{kazaam, `{"items":[],"range":"42:2024-01-01:"}`},
+	}
+	for i, test := range tests {
+		meta := fmt.Sprintf("test #%d: %T", i, test.remapper)
+		out, err := test.remapper.Remap(ctx, in)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", meta, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("%s: expected %s, got %s", meta, test.expected, out)
+		}
+	}
+}
+
+func TestKazaamTemplateRemapperParseError(t *testing.T) {
+	if _, err := NewKazaamTemplateRemapper(`[{"operation": "default", "spec": {"id": "{{ .Params.id "}}]`); err == nil {
+		t.Error("NewKazaamTemplateRemapper expected to return error for invalid template")
+	}
+}
@@ -0,0 +1,162 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RequestBodyTransformer converts the body of the client request to JSON and optionally remaps it.
+// The body is decoded according to its content type:
+//   - JSON is used as is, a body without the content type is considered JSON if it is valid JSON
+//   - application/x-www-form-urlencoded results in an object of the form fields, the first value is used
+//     for repeated fields
+//   - multipart/form-data results in an object of the form fields, the content of a file field is decoded
+//     in the same way as the body, a file which is neither JSON nor tabular data results in a string
+//   - other content e.g. CSV results in an array of objects, one per table row, created by the tablifier
+type RequestBodyTransformer struct {
+	remapper  Remapper
+	tablifier Tablifier
+}
+
+// NewRequestBodyTransformer creates the transformer, the remapper and the tablifier are optional
+func NewRequestBodyTransformer(remapper Remapper, tablifier Tablifier) *RequestBodyTransformer {
+	return &RequestBodyTransformer{remapper: remapper, tablifier: tablifier}
+}
+
+// Transform returns the body converted to JSON
+func (t *RequestBodyTransformer) Transform(ctx context.Context, contentType string, body []byte) ([]byte, error) {
+	mediaType, params, err := parseContentType(contentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "RequestBodyTransformer")
+	}
+	var out []byte
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, errors.Wrap(err, "RequestBodyTransformer: cannot parse form")
+		}
+		form := make(map[string]any, len(values))
+		for name := range values {
+			form[name] = values.Get(name)
+		}
+		out, err = json.Marshal(form)
+		if err != nil {
+			return nil, errors.Wrap(err, "RequestBodyTransformer: cannot encode form")
+		}
+	case "multipart/form-data":
+		if out, err = t.multipart(ctx, body, params["boundary"]); err != nil {
+			return nil, err
+		}
+	default:
+		if out, err = t.decode(ctx, mediaType, body); err != nil {
+			return nil, err
+		}
+	}
+	if t.remapper != nil {
+		if out, err = t.remapper.Remap(ctx, out); err != nil {
+			return nil, errors.Wrap(err, "RequestBodyTransformer: cannot remap body")
+		}
+	}
+	return out, nil
+}
+
+// decode converts JSON or tabular data to JSON
+func (t *RequestBodyTransformer) decode(ctx context.Context, mediaType string, data []byte) ([]byte, error) {
+	if isJSONMediaType(mediaType) || (mediaType == "" && json.Valid(data)) {
+		if !json.Valid(data) {
+			return nil, errors.Wrap(ErrUnsupportedDataType, "RequestBodyTransformer: invalid JSON")
+		}
+		return data, nil
+	}
+	if t.tablifier == nil {
+		return nil, errors.Wrapf(
+			ErrUnsupportedDataType,
+			"RequestBodyTransformer: cannot convert '%s' to JSON without tablifier",
+			mediaType,
+		)
+	}
+	table, err := t.tablifier.Table(ctx, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "RequestBodyTransformer: cannot get table")
+	}
+	rows := make([]map[string]any, table.NumRows())
+	for i := range rows {
+		rows[i] = table.Row(i)
+	}
+	out, err := json.Marshal(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "RequestBodyTransformer: cannot encode table")
+	}
+	return out, nil
+}
+
+func (t *RequestBodyTransformer) multipart(ctx context.Context, body []byte, boundary string) ([]byte, error) {
+	if boundary == "" {
+		return nil, errors.Wrap(ErrUnsupportedDataType, "RequestBodyTransformer: multipart boundary is missing")
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	form := make(map[string]any)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "RequestBodyTransformer: cannot read multipart body")
+		}
+		name := part.FormName()
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "RequestBodyTransformer: cannot read field '%s'", name)
+		}
+		if _, exist := form[name]; name == "" || exist {
+			continue
+		}
+		if part.FileName() == "" {
+			form[name] = string(data)
+			continue
+		}
+		mediaType, _, err := parseContentType(part.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "RequestBodyTransformer: field '%s'", name)
+		}
+		if !isJSONMediaType(mediaType) && t.tablifier == nil {
+			form[name] = string(data)
+			continue
+		}
+		decoded, err := t.decode(ctx, mediaType, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "RequestBodyTransformer: cannot decode field '%s'", name)
+		}
+		form[name] = json.RawMessage(decoded)
+	}
+	out, err := json.Marshal(form)
+	if err != nil {
+		return nil, errors.Wrap(err, "RequestBodyTransformer: cannot encode form")
+	}
+	return out, nil
+}
+
+func parseContentType(contentType string) (string, map[string]string, error) {
+	if contentType == "" {
+		return "", nil, nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "invalid content type '%s'", contentType)
+	}
+	return mediaType, params, nil
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
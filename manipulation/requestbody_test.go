@@ -0,0 +1,80 @@
+package manipulation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+const requestBodyMultipart = "--b\r\n" +
+	"Content-Disposition: form-data; name=\"account\"\r\n\r\n" +
+	"42\r\n" +
+	"--b\r\n" +
+	"Content-Disposition: form-data; name=\"file\"; filename=\"rows.csv\"\r\n" +
+	"Content-Type: text/csv\r\n\r\n" +
+	"id,name\n1,Alan\n2,Boris\n\r\n" +
+	"--b--\r\n"
+
+type requestBodyTest struct {
+	contentType string
+	in          string
+	out         string
+	err         bool
+}
+
+var requestBodyTests = []requestBodyTest{
+	{contentType: "application/json", in: `{"a":1}`, out: `{"a":1}`},
+	{contentType: "", in: `[1,2]`, out: `[1,2]`},
+	{contentType: "application/json", in: `{"a":`, err: true},
+	{contentType: "application/x-www-form-urlencoded", in: "a=1&b=x+y&a=2", out: `{"a":"1","b":"x y"}`},
+	{contentType: "text/csv; charset=utf-8", in: "id,name\n1,Alan\n", out: `[{"id":"1","name":"Alan"}]`},
+	{
+		contentType: "multipart/form-data; boundary=b",
+		in:          requestBodyMultipart,
+		out:         `{"account":"42","file":[{"id":"1","name":"Alan"},{"id":"2","name":"Boris"}]}`,
+	},
+	{contentType: "multipart/form-data", in: requestBodyMultipart, err: true},
+	{contentType: "text/csv;;", in: "a", err: true},
+}
+
+func TestRequestBodyTransformer(t *testing.T) {
+	tablifier, _ := NewCSVTablifier(CSVTablifierConfig{HeaderRow: 1})
+	transformer := NewRequestBodyTransformer(nil, tablifier)
+	for i, tt := range requestBodyTests {
+		meta := fmt.Sprintf("test #%d: Transform(%q),", i, tt.contentType)
+		out, err := transformer.Transform(context.Background(), tt.contentType, []byte(tt.in))
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s expected error, got %s", meta, out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if string(out) != tt.out {
+			t.Errorf("%s expected %s, got %s", meta, tt.out, out)
+		}
+	}
+}
+
+func TestRequestBodyTransformerRemapper(t *testing.T) {
+	remapper, err := NewJQRemapper(`{items: [.[] | {id: (.id | tonumber)}]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tablifier, _ := NewCSVTablifier(CSVTablifierConfig{HeaderRow: 1})
+	out, err := NewRequestBodyTransformer(remapper, tablifier).
+		Transform(context.Background(), "text/csv", []byte("id\n1\n2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := `{"items":[{"id":1},{"id":2}]}`; string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+
+	if _, err = NewRequestBodyTransformer(nil, nil).Transform(context.Background(), "text/csv", []byte("id\n1\n")); err == nil {
+		t.Error("expected error for CSV without tablifier")
+	}
+}
@@ -0,0 +1,90 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// jsonSchemaResource is the name under which the schema is registered, it is used for resolving local references
+const jsonSchemaResource = "schema.json"
+
+// SchemaViolation describes a single violation of the schema, Path is the JSON pointer to the violating value
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+// SchemaValidationError is returned when the data does not conform to the schema
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Path + ": " + v.Message
+	}
+	return "data does not conform to the schema: " + strings.Join(messages, "; ")
+}
+
+// JSONSchemaValidator validates JSON data against the JSON Schema https://json-schema.org/
+type JSONSchemaValidator struct {
+	schema *jsonschema.Schema
+}
+
+func NewJSONSchemaValidator(schema string) (*JSONSchemaValidator, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(jsonSchemaResource, strings.NewReader(schema)); err != nil {
+		return nil, errors.Wrap(err, "JSONSchemaValidator: cannot load schema")
+	}
+	compiled, err := compiler.Compile(jsonSchemaResource)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSONSchemaValidator: cannot compile schema")
+	}
+	return &JSONSchemaValidator{schema: compiled}, nil
+}
+
+func (v *JSONSchemaValidator) Validate(_ context.Context, in []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(in))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return &SchemaValidationError{Violations: []SchemaViolation{{Path: "/", Message: "invalid JSON: " + err.Error()}}}
+	}
+	err := v.schema.Validate(data)
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return errors.Wrap(err, "JSONSchemaValidator: cannot validate data")
+	}
+	result := &SchemaValidationError{}
+	collectViolations(validationErr, result)
+	// the order of the causes depends on the map iteration order of the schema keywords
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		return result.Violations[i].Path < result.Violations[j].Path
+	})
+	return result
+}
+
+// collectViolations collects the leaf errors which describe the actual violations
+func collectViolations(err *jsonschema.ValidationError, result *SchemaValidationError) {
+	if len(err.Causes) == 0 {
+		path := err.InstanceLocation
+		if path == "" {
+			path = "/"
+		}
+		result.Violations = append(result.Violations, SchemaViolation{Path: path, Message: err.Message})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectViolations(cause, result)
+	}
+}
@@ -0,0 +1,64 @@
+package manipulation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchemaValidator(t *testing.T) {
+	validator, err := NewJSONSchemaValidator(`{
+  "type": "object",
+  "required": ["data"],
+  "properties": {
+    "data": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["id"],
+        "properties": {"id": {"type": "integer"}, "title": {"type": "string"}}
+      }
+    }
+  }
+}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cases := []struct {
+		input      string
+		violations []string
+	}{
+		{`{"data":[{"id":1,"title":"a"},{"id":2}]}`, nil},
+		{`{"items":[]}`, []string{"/"}},
+		{`{"data":[{"id":1},{"id":"2","title":3}]}`, []string{"/data/1/id", "/data/1/title"}},
+		{`{"data":[{"title":"a"}]}`, []string{"/data/0"}},
+		{`not json`, []string{"/"}},
+	}
+	for i, c := range cases {
+		meta := fmt.Sprintf("test #%d", i)
+		err := validator.Validate(context.Background(), []byte(c.input))
+		if c.violations == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", meta, err)
+			}
+			continue
+		}
+		validationErr, ok := err.(*SchemaValidationError)
+		if !ok {
+			t.Errorf("%s: expected *SchemaValidationError, got %v", meta, err)
+			continue
+		}
+		var paths []string
+		for _, v := range validationErr.Violations {
+			paths = append(paths, v.Path)
+		}
+		if !reflect.DeepEqual(paths, c.violations) {
+			t.Errorf("%s: expected violations at %v, got %v (%s)", meta, c.violations, paths, err)
+		}
+	}
+
+	if _, err = NewJSONSchemaValidator(`{"type": "unknown"}`); err == nil {
+		t.Error("NewJSONSchemaValidator expected to return error for invalid schema")
+	}
+}
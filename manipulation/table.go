@@ -0,0 +1,180 @@
+package manipulation
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/dframe"
+)
+
+// TableOperation modifies or replaces the table created by a Tablifier
+type TableOperation interface {
+	Apply(table *dframe.Table) (*dframe.Table, error)
+}
+
+// TableOperationFunc allows using functions as table operations
+type TableOperationFunc func(table *dframe.Table) (*dframe.Table, error)
+
+func (f TableOperationFunc) Apply(table *dframe.Table) (*dframe.Table, error) {
+	return f(table)
+}
+
+// ProcessingTablifier applies the operations to the tables created by the underlying tablifier
+type ProcessingTablifier struct {
+	tablifier  Tablifier
+	operations []TableOperation
+}
+
+func NewProcessingTablifier(tablifier Tablifier, operations ...TableOperation) *ProcessingTablifier {
+	return &ProcessingTablifier{tablifier, operations}
+}
+
+func (t *ProcessingTablifier) Table(ctx context.Context, in []byte) (*dframe.Table, error) {
+	table, err := t.tablifier.Table(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return t.Process(table)
+}
+
+// Process applies the operations to the given table in order
+func (t *ProcessingTablifier) Process(table *dframe.Table) (*dframe.Table, error) {
+	var err error
+	for _, operation := range t.operations {
+		table, err = operation.Apply(table)
+		if err != nil {
+			return nil, errors.Wrap(err, "ProcessingTablifier: cannot process table")
+		}
+	}
+	return table, nil
+}
+
+type ComputedColumnConfig struct {
+	Name       string // Name of the column, the existing column is replaced
+	Expression string // Expression evaluated over each row, see Expression
+}
+
+type AggregationConfig struct {
+	Column string // Column to aggregate
+	Func   string // One of: sum, avg, min, max, count, first, last
+	As     string // Resulting column name, Column is used by default
+}
+
+type GroupByConfig struct {
+	Columns   []string            // Columns to group rows by
+	Aggregate []AggregationConfig // Aggregations to apply within each group
+}
+
+type PivotConfig struct {
+	Index       string // Column which distinct values become rows
+	Columns     string // Column which distinct values become columns
+	Values      string // Column which values are aggregated into the cells
+	Func        string // Aggregation function, "first" by default
+	SortColumns bool   // True to sort the resulting columns by name instead of the order of appearance
+}
+
+type TotalsConfig struct {
+	Label       string              // Text to put into the label column
+	LabelColumn string              // Column to put the label into (the first column by default)
+	Aggregate   []AggregationConfig // Aggregations to put into the totals row
+}
+
+// ComputedColumns creates an operation that adds columns computed from the other columns,
+// columns are computed in order so that an expression may refer to the previously computed columns
+func ComputedColumns(configs []ComputedColumnConfig) (TableOperation, error) {
+	expressions := make([]*Expression, len(configs))
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, errors.Errorf("ComputedColumns: column #%d name is empty", i)
+		}
+		expr, err := CompileExpression(cfg.Expression)
+		if err != nil {
+			return nil, errors.Wrapf(err, "ComputedColumns: column '%s'", cfg.Name)
+		}
+		expressions[i] = expr
+	}
+	return TableOperationFunc(func(table *dframe.Table) (*dframe.Table, error) {
+		for i, cfg := range configs {
+			column := dframe.NewColumn(cfg.Name, dframe.Nullable)
+			for row := 0; row < table.NumRows(); row++ {
+				v, err := expressions[i].Eval(table.Row(row))
+				if err != nil {
+					return nil, errors.Wrapf(err, "ComputedColumns: column '%s', row %d", cfg.Name, row)
+				}
+				column.Add(v)
+			}
+			if err := table.Set(column); err != nil {
+				return nil, errors.Wrap(err, "ComputedColumns")
+			}
+		}
+		return table, nil
+	}), nil
+}
+
+// GroupBy creates an operation that groups rows and aggregates the values of each group
+func GroupBy(cfg GroupByConfig) (TableOperation, error) {
+	aggregations, err := aggregations(cfg.Aggregate)
+	if err != nil {
+		return nil, errors.Wrap(err, "GroupBy")
+	}
+	return TableOperationFunc(func(table *dframe.Table) (*dframe.Table, error) {
+		return table.GroupBy(cfg.Columns...).Agg(aggregations...)
+	}), nil
+}
+
+// Pivot creates an operation that reshapes the table so that
+// the distinct values of one column become rows and the values of another become columns
+func Pivot(cfg PivotConfig) (TableOperation, error) {
+	funcName := cfg.Func
+	if funcName == "" {
+		funcName = dframe.AggFirst
+	}
+	agg, err := dframe.AggFuncByName(funcName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Pivot")
+	}
+	return TableOperationFunc(func(table *dframe.Table) (*dframe.Table, error) {
+		table, err := table.Pivot(cfg.Index, cfg.Columns, cfg.Values, agg)
+		if err != nil || !cfg.SortColumns {
+			return table, err
+		}
+		header := table.Header()
+		columns := header[1:]
+		sort.SliceStable(columns, func(i, j int) bool {
+			return dframe.Compare(columns[i], columns[j]) < 0
+		})
+		return table, table.Select(header...)
+	}), nil
+}
+
+// Transpose creates an operation that turns rows into columns and columns into rows
+func Transpose() TableOperation {
+	return TableOperationFunc(func(table *dframe.Table) (*dframe.Table, error) {
+		return table.Transpose()
+	})
+}
+
+// Totals creates an operation that appends a totals row to the table
+func Totals(cfg TotalsConfig) (TableOperation, error) {
+	aggregations, err := aggregations(cfg.Aggregate)
+	if err != nil {
+		return nil, errors.Wrap(err, "Totals")
+	}
+	return TableOperationFunc(func(table *dframe.Table) (*dframe.Table, error) {
+		return table, table.AppendTotals(cfg.Label, cfg.LabelColumn, aggregations...)
+	}), nil
+}
+
+func aggregations(configs []AggregationConfig) ([]dframe.Aggregation, error) {
+	result := make([]dframe.Aggregation, len(configs))
+	for i, cfg := range configs {
+		f, err := dframe.AggFuncByName(cfg.Func)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = dframe.Aggregation{Column: cfg.Column, Func: f, As: cfg.As}
+	}
+	return result, nil
+}
@@ -0,0 +1,380 @@
+package manipulation
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// The plugin ABI
+//
+// A module must export its linear memory as "memory" and the allocation function "alloc(size i32) i32"
+// which is used by the host to pass data to the module. The memory allocated for the input is owned by the module.
+//
+// Remappers export "remap(ptr i32, len i32) i64" which returns the location of the output
+// packed as (ptr << 32 | len).
+//
+// Transformers export "transform(ptr i32, len i32)" which is called for every page and writes the output
+// by means of the host function "alternea.write", optional exports "begin()" and "end()" are called
+// before the first and after the last page. A single module instance serves all pages of a request.
+//
+// The host module "alternea" provides:
+//   - "write(ptr i32, len i32) i32" writes the output of the transformer, returns 0 on success
+//   - "fail(ptr i32, len i32)" fails the current call with the given error message
+//
+// WASI is available without filesystem access, so modules built for wasm32-wasi can be used,
+// such modules must be built as libraries (reactors), the "_initialize" function is called on instantiation.
+//
+// The signatures of the exported functions are checked when the module, the remapper or the transformer is created.
+const (
+	WASMHostModuleName   = "alternea"
+	WASMMemoryExport     = "memory"
+	WASMAllocExport      = "alloc"
+	WASMRemapExport      = "remap"
+	WASMTransformExport  = "transform"
+	WASMBeginExport      = "begin"
+	WASMEndExport        = "end"
+	WASMInitializeExport = "_initialize"
+
+	DefaultWASMTimeout = 10 * time.Second
+
+	wasmPageSize = 65536
+)
+
+type WASMModuleConfig struct {
+	// Timeout limits the execution time of a single call of the module function
+	Timeout time.Duration
+	// MemoryLimit limits the memory of the module instance in bytes, 0 means the maximum of 4 GiB
+	MemoryLimit uint64
+}
+
+// WASMModule is a compiled WebAssembly module, every remapping or transformation uses a new instance of the module
+type WASMModule struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+func NewWASMModule(ctx context.Context, binary []byte, cfg WASMModuleConfig) (*WASMModule, error) {
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if cfg.MemoryLimit > 0 {
+		pages := (cfg.MemoryLimit + wasmPageSize - 1) / wasmPageSize
+		if pages > wasmPageSize {
+			pages = wasmPageSize
+		}
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(uint32(pages))
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultWASMTimeout
+	}
+	m := &WASMModule{runtime: wazero.NewRuntimeWithConfig(ctx, runtimeConfig), timeout: cfg.Timeout}
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, m.runtime); err != nil {
+		_ = m.Close(ctx)
+		return nil, errors.Wrap(err, "WASMModule: cannot instantiate WASI")
+	}
+	_, err := m.runtime.NewHostModuleBuilder(WASMHostModuleName).
+		NewFunctionBuilder().WithFunc(wasmWrite).Export("write").
+		NewFunctionBuilder().WithFunc(wasmFail).Export("fail").
+		Instantiate(ctx)
+	if err != nil {
+		_ = m.Close(ctx)
+		return nil, errors.Wrap(err, "WASMModule: cannot instantiate host module")
+	}
+
+	if m.compiled, err = m.runtime.CompileModule(ctx, binary); err != nil {
+		_ = m.Close(ctx)
+		return nil, errors.Wrap(err, "WASMModule: cannot compile module")
+	}
+	if _, ok := m.compiled.ExportedMemories()[WASMMemoryExport]; !ok {
+		_ = m.Close(ctx)
+		return nil, errors.Errorf("WASMModule: module does not export '%s'", WASMMemoryExport)
+	}
+	if err = m.requireFunction(WASMAllocExport, wasmSignature(wasmI32).returning(wasmI32)); err != nil {
+		_ = m.Close(ctx)
+		return nil, err
+	}
+	return m, nil
+}
+
+// Close releases the resources of the module
+func (m *WASMModule) Close(ctx context.Context) error {
+	return m.runtime.Close(ctx)
+}
+
+// requireFunction returns the error if the module does not export the function with the given signature
+func (m *WASMModule) requireFunction(name string, signature wasmFunctionSignature) error {
+	fn, ok := m.compiled.ExportedFunctions()[name]
+	if !ok {
+		return errors.Errorf("WASMModule: module does not export function '%s'", name)
+	}
+	return checkSignature(fn, signature)
+}
+
+// checkOptionalFunction returns the error if the module exports the function with the signature other than given
+func (m *WASMModule) checkOptionalFunction(name string, signature wasmFunctionSignature) error {
+	if fn, ok := m.compiled.ExportedFunctions()[name]; ok {
+		return checkSignature(fn, signature)
+	}
+	return nil
+}
+
+const (
+	wasmI32 = api.ValueTypeI32
+	wasmI64 = api.ValueTypeI64
+)
+
+type wasmFunctionSignature struct {
+	params  []api.ValueType
+	results []api.ValueType
+}
+
+func wasmSignature(params ...api.ValueType) wasmFunctionSignature {
+	return wasmFunctionSignature{params: params}
+}
+
+func (s wasmFunctionSignature) returning(results ...api.ValueType) wasmFunctionSignature {
+	s.results = results
+	return s
+}
+
+func (s wasmFunctionSignature) String() string {
+	return formatValueTypes(s.params) + " -> " + formatValueTypes(s.results)
+}
+
+func checkSignature(fn api.FunctionDefinition, signature wasmFunctionSignature) error {
+	if !equalValueTypes(fn.ParamTypes(), signature.params) || !equalValueTypes(fn.ResultTypes(), signature.results) {
+		actual := wasmFunctionSignature{params: fn.ParamTypes(), results: fn.ResultTypes()}
+		return errors.Errorf(
+			"WASMModule: function '%s' has signature %s, want %s",
+			fn.ExportNames()[0],
+			actual,
+			signature,
+		)
+	}
+	return nil
+}
+
+func equalValueTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatValueTypes(types []api.ValueType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = api.ValueTypeName(t)
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+func (m *WASMModule) instantiate(ctx context.Context) (*wasmInstance, error) {
+	instance := &wasmInstance{timeout: m.timeout}
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions(WASMInitializeExport).
+		WithSysWalltime()
+	ctx, cancel := context.WithTimeout(instance.context(ctx), m.timeout)
+	defer cancel()
+	var err error
+	if instance.module, err = m.runtime.InstantiateModule(ctx, m.compiled, config); err != nil {
+		return nil, errors.Wrap(err, "WASMModule: cannot instantiate module")
+	}
+	return instance, nil
+}
+
+type wasmInstance struct {
+	module  api.Module
+	timeout time.Duration
+	output  io.Writer
+	failure string
+}
+
+type wasmInstanceKey struct{}
+
+// context returns the context which is passed to the module functions, host functions get the instance from it
+func (i *wasmInstance) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, wasmInstanceKey{}, i)
+}
+
+// call calls the exported function, errors reported by the module by means of "alternea.fail" are returned as errors
+func (i *wasmInstance) call(ctx context.Context, name string, params ...uint64) ([]uint64, error) {
+	fn := i.module.ExportedFunction(name)
+	if fn == nil {
+		return nil, errors.Errorf("module does not export function '%s'", name)
+	}
+	ctx, cancel := context.WithTimeout(i.context(ctx), i.timeout)
+	defer cancel()
+	i.failure = ""
+	results, err := fn.Call(ctx, params...)
+	if i.failure != "" {
+		return nil, errors.Errorf("%s: %s", name, i.failure)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errors.Wrapf(ErrExecutionTimeout, "%s", name)
+		}
+		return nil, errors.Wrapf(err, "%s", name)
+	}
+	return results, nil
+}
+
+// write copies data into the memory allocated by the module
+func (i *wasmInstance) write(ctx context.Context, data []byte) (uint32, error) {
+	results, err := i.call(ctx, WASMAllocExport, api.EncodeU32(uint32(len(data))))
+	if err != nil {
+		return 0, err
+	}
+	ptr := api.DecodeU32(results[0])
+	if !i.module.Memory().Write(ptr, data) {
+		return 0, errors.Errorf("%s: returned pointer is out of memory range", WASMAllocExport)
+	}
+	return ptr, nil
+}
+
+func (i *wasmInstance) read(ptr, size uint32) ([]byte, error) {
+	data, ok := i.module.Memory().Read(ptr, size)
+	if !ok {
+		return nil, errors.New("output is out of memory range")
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (i *wasmInstance) close(ctx context.Context) {
+	_ = i.module.Close(ctx)
+}
+
+func wasmWrite(ctx context.Context, mod api.Module, ptr, size uint32) uint32 {
+	instance, _ := ctx.Value(wasmInstanceKey{}).(*wasmInstance)
+	data, ok := mod.Memory().Read(ptr, size)
+	if instance == nil || instance.output == nil || !ok {
+		return 1
+	}
+	if _, err := instance.output.Write(data); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func wasmFail(ctx context.Context, mod api.Module, ptr, size uint32) {
+	instance, _ := ctx.Value(wasmInstanceKey{}).(*wasmInstance)
+	if instance == nil {
+		return
+	}
+	instance.failure = "module failed"
+	if message, ok := mod.Memory().Read(ptr, size); ok && len(message) > 0 {
+		instance.failure = string(message)
+	}
+}
+
+// WASMRemapper transforms data by means of the "remap" function of the WebAssembly module
+type WASMRemapper struct {
+	module *WASMModule
+}
+
+func NewWASMRemapper(module *WASMModule) (*WASMRemapper, error) {
+	if err := module.requireFunction(WASMRemapExport, wasmSignature(wasmI32, wasmI32).returning(wasmI64)); err != nil {
+		return nil, errors.Wrap(err, "WASMRemapper")
+	}
+	return &WASMRemapper{module: module}, nil
+}
+
+func (r *WASMRemapper) Remap(ctx context.Context, in []byte) ([]byte, error) {
+	instance, err := r.module.instantiate(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "WASMRemapper")
+	}
+	defer instance.close(ctx)
+
+	ptr, err := instance.write(ctx, in)
+	if err != nil {
+		return nil, errors.Wrap(err, "WASMRemapper: cannot pass input")
+	}
+	results, err := instance.call(ctx, WASMRemapExport, api.EncodeU32(ptr), api.EncodeU32(uint32(len(in))))
+	if err != nil {
+		return nil, errors.Wrap(err, "WASMRemapper: cannot remap")
+	}
+	out, err := instance.read(uint32(results[0]>>32), uint32(results[0]))
+	if err != nil {
+		return nil, errors.Wrap(err, "WASMRemapper: cannot read output")
+	}
+	return out, nil
+}
+
+// WASMTransformer transforms pages by means of the "transform" function of the WebAssembly module
+type WASMTransformer struct {
+	module    *WASMModule
+	mediaType string
+	extension string
+}
+
+func NewWASMTransformer(module *WASMModule) (*WASMTransformer, error) {
+	if err := module.requireFunction(WASMTransformExport, wasmSignature(wasmI32, wasmI32)); err != nil {
+		return nil, errors.Wrap(err, "WASMTransformer")
+	}
+	for _, name := range []string{WASMBeginExport, WASMEndExport} {
+		if err := module.checkOptionalFunction(name, wasmSignature()); err != nil {
+			return nil, errors.Wrap(err, "WASMTransformer")
+		}
+	}
+	return &WASMTransformer{module: module}, nil
+}
+
+// SetMediaType describes the output of the module e.g. "application/zip" and ".zip"
+func (t *WASMTransformer) SetMediaType(mediaType, extension string) {
+	t.mediaType = mediaType
+	t.extension = extension
+}
+
+func (t *WASMTransformer) MediaType() string {
+	return t.mediaType
+}
+
+func (t *WASMTransformer) FileExtension() string {
+	return t.extension
+}
+
+func (t *WASMTransformer) Transform(ctx context.Context, pages <-chan []byte, w io.Writer) error {
+	instance, err := t.module.instantiate(ctx)
+	if err != nil {
+		return errors.Wrap(err, "WASMTransformer")
+	}
+	defer instance.close(ctx)
+	instance.output = w
+
+	exports := t.module.compiled.ExportedFunctions()
+	if _, ok := exports[WASMBeginExport]; ok {
+		if _, err = instance.call(ctx, WASMBeginExport); err != nil {
+			return errors.Wrap(err, "WASMTransformer: cannot begin transformation")
+		}
+	}
+	for page := range pages {
+		ptr, err := instance.write(ctx, page)
+		if err != nil {
+			return errors.Wrap(err, "WASMTransformer: cannot pass page")
+		}
+		_, err = instance.call(ctx, WASMTransformExport, api.EncodeU32(ptr), api.EncodeU32(uint32(len(page))))
+		if err != nil {
+			return errors.Wrap(err, "WASMTransformer: cannot transform page")
+		}
+	}
+	if _, ok := exports[WASMEndExport]; ok {
+		if _, err = instance.call(ctx, WASMEndExport); err != nil {
+			return errors.Wrap(err, "WASMTransformer: cannot end transformation")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// testWASMModule builds a module which exports:
+//   - alloc(size) returning the fixed offset 1024
+//   - remap(ptr, len) returning the input as is or failing with "empty input" if the input is empty
+//   - transform(ptr, len) writing the page as is
+func testWASMModule() []byte {
+	return testWASMModuleExporting(2, 3, 4)
+}
+
+// testWASMModuleExporting builds the same module as testWASMModule which exports the functions
+// with the given indexes as alloc, remap and transform
+func testWASMModuleExporting(alloc, remap, transform byte) []byte {
+	section := func(id byte, content ...byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	body := func(code ...byte) []byte {
+		return append([]byte{byte(len(code))}, code...)
+	}
+
+	types := section(1,
+		4,
+		0x60, 2, 0x7f, 0x7f, 0, // (i32, i32)
+		0x60, 2, 0x7f, 0x7f, 1, 0x7f, // (i32, i32) i32
+		0x60, 1, 0x7f, 1, 0x7f, // (i32) i32
+		0x60, 2, 0x7f, 0x7f, 1, 0x7e, // (i32, i32) i64
+	)
+	imports := section(2, concat(
+		[]byte{2},
+		name(WASMHostModuleName), name("write"), []byte{0, 1},
+		name(WASMHostModuleName), name("fail"), []byte{0, 0},
+	)...)
+	functions := section(3, 3, 2, 3, 0)
+	memory := section(5, 1, 0, 1)
+	exports := section(7, concat(
+		[]byte{4},
+		name(WASMMemoryExport), []byte{2, 0},
+		name(WASMAllocExport), []byte{0, alloc},
+		name(WASMRemapExport), []byte{0, remap},
+		name(WASMTransformExport), []byte{0, transform},
+	)...)
+	code := section(10, concat(
+		[]byte{3},
+		body(0, 0x41, 0x80, 0x08, 0x0b),
+		body(0,
+			0x20, 1, 0x45, 0x04, 0x40, // if len == 0
+			0x41, 0, 0x41, 11, 0x10, 1, // fail(0, 11)
+			0x42, 0, 0x0f, 0x0b, // return 0
+			0x20, 0, 0xad, 0x42, 32, 0x86, // ptr << 32
+			0x20, 1, 0xad, 0x84, 0x0b, // | len
+		),
+		body(0, 0x20, 0, 0x20, 1, 0x10, 0, 0x1a, 0x0b),
+	)...)
+	data := section(11, concat([]byte{1, 0, 0x41, 0, 0x0b}, name("empty input"))...)
+
+	return concat([]byte("\x00asm\x01\x00\x00\x00"), types, imports, functions, memory, exports, code, data)
+}
+
+func TestWASMRemapper(t *testing.T) {
+	ctx := context.Background()
+	module, err := NewWASMModule(ctx, testWASMModule(), WASMModuleConfig{MemoryLimit: 1 << 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer module.Close(ctx)
+
+	remapper, err := NewWASMRemapper(module)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out, err := remapper.Remap(ctx, []byte(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(out) != `{"id":1}` {
+		t.Errorf("expected %s, got %s", `{"id":1}`, out)
+	}
+
+	_, err = remapper.Remap(ctx, nil)
+	if err == nil || !strings.Contains(err.Error(), "empty input") {
+		t.Errorf("expected error reported by the module, got %v", err)
+	}
+}
+
+func TestWASMTransformer(t *testing.T) {
+	ctx := context.Background()
+	module, err := NewWASMModule(ctx, testWASMModule(), WASMModuleConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer module.Close(ctx)
+
+	transformer, err := NewWASMTransformer(module)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pages := make(chan []byte, 2)
+	pages <- []byte("first;")
+	pages <- []byte("second")
+	close(pages)
+	w := &bytes.Buffer{}
+	if err = transformer.Transform(ctx, pages, w); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if w.String() != "first;second" {
+		t.Errorf("expected %s, got %s", "first;second", w.String())
+	}
+}
+
+func TestWASMModuleErrors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewWASMModule(ctx, []byte("not a module"), WASMModuleConfig{}); err == nil {
+		t.Error("NewWASMModule expected to return error for invalid binary")
+	}
+	if _, err := NewWASMModule(ctx, testWASMModuleExporting(3, 3, 4), WASMModuleConfig{}); err == nil {
+		t.Error("NewWASMModule expected to return error for alloc with unexpected signature")
+	}
+
+	module, err := NewWASMModule(ctx, testWASMModuleExporting(2, 2, 3), WASMModuleConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer module.Close(ctx)
+	if _, err = NewWASMRemapper(module); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("NewWASMRemapper expected to return error for remap with unexpected signature, got %v", err)
+	}
+	if _, err = NewWASMTransformer(module); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("NewWASMTransformer expected to return error for transform with unexpected signature, got %v", err)
+	}
+}
@@ -0,0 +1,349 @@
+package manipulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/htmlindex"
+
+	"github.com/velmie/alternea/dframe"
+)
+
+type XMLRemapperConfig struct {
+	AttributePrefix  string   // Prefix of the properties created from attributes (set to "@" by default)
+	TextKey          string   // Property name of the element text when the element has attributes or children
+	IgnoreAttributes bool     // True to skip attributes
+	ForceArray       []string // Names of the elements which are always converted to arrays
+}
+
+// XMLRemapper converts XML into JSON.
+// Elements become object properties named after the element local names (namespace prefixes are dropped),
+// repeated elements become arrays, elements without attributes and children become strings.
+// e.g. <book id="1"><title>Go</title><tag>a</tag><tag>b</tag></book>
+// becomes {"book":{"@id":"1","title":"Go","tag":["a","b"]}}
+type XMLRemapper struct {
+	config     XMLRemapperConfig
+	forceArray map[string]bool
+}
+
+func NewXMLRemapper(config XMLRemapperConfig) *XMLRemapper {
+	if config.TextKey == "" {
+		config.TextKey = "#text"
+	}
+	forceArray := make(map[string]bool, len(config.ForceArray))
+	for _, name := range config.ForceArray {
+		forceArray[name] = true
+	}
+	return &XMLRemapper{config: config, forceArray: forceArray}
+}
+
+func (r *XMLRemapper) Remap(_ context.Context, in []byte) ([]byte, error) {
+	doc, err := parseXML(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "XMLRemapper")
+	}
+	out, err := json.Marshal(r.object(doc))
+	if err != nil {
+		return nil, errors.Wrap(err, "XMLRemapper: cannot encode JSON")
+	}
+	return out, nil
+}
+
+func (r *XMLRemapper) value(n *xmlNode) any {
+	hasAttrs := len(n.attrs) > 0 && !r.config.IgnoreAttributes
+	if !hasAttrs && len(n.children) == 0 {
+		return n.text()
+	}
+	obj := r.object(n)
+	if text := n.text(); text != "" {
+		obj.set(r.config.TextKey, text)
+	}
+	return obj
+}
+
+func (r *XMLRemapper) object(n *xmlNode) *orderedObject {
+	obj := &orderedObject{values: make(map[string]any)}
+	if !r.config.IgnoreAttributes {
+		for _, attr := range n.attrs {
+			obj.set(r.config.AttributePrefix+attr.Name.Local, attr.Value)
+		}
+	}
+	for _, child := range n.children {
+		name := child.name.Local
+		value := r.value(child)
+		existing, ok := obj.values[name]
+		switch {
+		case ok && obj.arrays[name]:
+			obj.values[name] = append(existing.([]any), value)
+		case ok:
+			obj.values[name] = []any{existing, value}
+			obj.markArray(name)
+		case r.forceArray[name]:
+			obj.set(name, []any{value})
+			obj.markArray(name)
+		default:
+			obj.set(name, value)
+		}
+	}
+	return obj
+}
+
+// orderedObject keeps properties in the order in which they were set
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+	arrays map[string]bool
+}
+
+func (o *orderedObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *orderedObject) markArray(key string) {
+	if o.arrays == nil {
+		o.arrays = make(map[string]bool)
+	}
+	o.arrays[key] = true
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type XMLColumnConfig struct {
+	Name string // Column name
+	Path string // Path relative to the row element e.g. "Address/City", "@id" or "text()"
+}
+
+type XMLRowsTablifierConfig struct {
+	// Rows is a path to the repeated elements, each element becomes a row
+	// e.g. "/Envelope/Body/Orders/Order" or "//Order"
+	Rows string
+	// Columns determines which columns should be added to the result,
+	// by default columns are created from attributes and child elements of the rows
+	Columns []XMLColumnConfig
+}
+
+// XMLRowsTablifier creates table from XML selecting repeated elements by XPath-like paths.
+// Path segments are separated by "/" and match element local names, "*" matches any element,
+// "//" matches any number of levels, "@name" selects an attribute and "text()" the element text.
+type XMLRowsTablifier struct {
+	config XMLRowsTablifierConfig
+}
+
+func NewXMLRowsTablifier(config XMLRowsTablifierConfig) (*XMLRowsTablifier, error) {
+	if config.Rows == "" {
+		return nil, errors.New("XMLRowsTablifier: rows path is required")
+	}
+	for _, c := range config.Columns {
+		if c.Name == "" || c.Path == "" {
+			return nil, fmt.Errorf("XMLRowsTablifier: column name and path are required, got %+v", c)
+		}
+	}
+	return &XMLRowsTablifier{config: config}, nil
+}
+
+func (t *XMLRowsTablifier) Table(_ context.Context, in []byte) (*dframe.Table, error) {
+	doc, err := parseXML(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "XMLRowsTablifier")
+	}
+	rows := doc.find(t.config.Rows)
+
+	columnConfigs := t.config.Columns
+	if len(columnConfigs) == 0 {
+		columnConfigs = defaultXMLColumns(rows)
+	}
+	columns := make([]*dframe.Column, len(columnConfigs))
+	for i, cfg := range columnConfigs {
+		columns[i] = dframe.NewColumn(cfg.Name, dframe.Nullable)
+		for _, row := range rows {
+			v, ok := row.value(cfg.Path)
+			if !ok {
+				columns[i].Add(nil)
+				continue
+			}
+			columns[i].Add(v)
+		}
+	}
+	table, err := dframe.NewTable(columns...)
+	if err != nil {
+		return nil, errors.Wrap(err, "XMLRowsTablifier: cannot create table")
+	}
+	return table, nil
+}
+
+func defaultXMLColumns(rows []*xmlNode) []XMLColumnConfig {
+	var columns []XMLColumnConfig
+	seen := make(map[string]bool)
+	add := func(name, path string) {
+		if !seen[name] {
+			seen[name] = true
+			columns = append(columns, XMLColumnConfig{Name: name, Path: path})
+		}
+	}
+	for _, row := range rows {
+		for _, attr := range row.attrs {
+			add(attr.Name.Local, "@"+attr.Name.Local)
+		}
+		for _, child := range row.children {
+			add(child.name.Local, child.name.Local)
+		}
+	}
+	return columns
+}
+
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	content  strings.Builder
+}
+
+func (n *xmlNode) text() string {
+	return strings.TrimSpace(n.content.String())
+}
+
+// find returns elements selected by the path, the node is considered a document if the path is absolute
+func (n *xmlNode) find(path string) []*xmlNode {
+	nodes := []*xmlNode{n}
+	descendants := false
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		switch segment {
+		case "":
+			descendants = true
+			continue
+		case ".":
+			continue
+		}
+		var selected []*xmlNode
+		for _, node := range nodes {
+			selected = node.appendMatching(selected, segment, descendants)
+		}
+		nodes = selected
+		descendants = false
+	}
+	return nodes
+}
+
+func (n *xmlNode) appendMatching(dest []*xmlNode, name string, descendants bool) []*xmlNode {
+	for _, child := range n.children {
+		if name == "*" || child.name.Local == name {
+			dest = append(dest, child)
+		}
+		if descendants {
+			dest = child.appendMatching(dest, name, true)
+		}
+	}
+	return dest
+}
+
+// value returns value of the attribute or text of the first element selected by the relative path
+func (n *xmlNode) value(path string) (string, bool) {
+	elementPath, last := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		elementPath, last = path[:i], path[i+1:]
+	}
+	node := n
+	if elementPath != "" {
+		nodes := n.find(elementPath)
+		if len(nodes) == 0 {
+			return "", false
+		}
+		node = nodes[0]
+	}
+	switch {
+	case strings.HasPrefix(last, "@"):
+		for _, attr := range node.attrs {
+			if attr.Name.Local == last[1:] {
+				return attr.Value, true
+			}
+		}
+		return "", false
+	case last == "text()" || last == ".":
+		return node.text(), true
+	}
+	nodes := node.find(last)
+	if len(nodes) == 0 {
+		return "", false
+	}
+	return nodes[0].text(), true
+}
+
+// charsetReader converts the input in the charset declared by the document e.g. ISO-8859-1 or windows-1251 to UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	if strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+		return input, nil
+	}
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+// parseXML returns the document node which children are the root elements
+func parseXML(in []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(in))
+	decoder.Strict = false
+	decoder.CharsetReader = charsetReader
+	doc := &xmlNode{}
+	stack := []*xmlNode{doc}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse XML")
+		}
+		current := stack[len(stack)-1]
+		switch tok := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: tok.Name}
+			for _, attr := range tok.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.attrs = append(node.attrs, attr)
+			}
+			current.children = append(current.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			current.content.Write(tok)
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,92 @@
+package manipulation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const xmlTestInput = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <Orders>
+      <Order id="1"><Customer>Alan</Customer><Total currency="EUR">10.5</Total></Order>
+      <Order id="2"><Customer>Tim</Customer></Order>
+    </Orders>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestXMLRemapper(t *testing.T) {
+	remapper := NewXMLRemapper(XMLRemapperConfig{AttributePrefix: "@", ForceArray: []string{"Customer"}})
+	out, err := remapper.Remap(context.Background(), []byte(xmlTestInput))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `{"Envelope":{"Body":{"Orders":{"Order":[` +
+		`{"@id":"1","Customer":["Alan"],"Total":{"@currency":"EUR","#text":"10.5"}},` +
+		`{"@id":"2","Customer":["Tim"]}]}}}}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
+
+func TestXMLRowsTablifier(t *testing.T) {
+	tablifier, err := NewXMLRowsTablifier(XMLRowsTablifierConfig{
+		Rows: "//Order",
+		Columns: []XMLColumnConfig{
+			{Name: "id", Path: "@id"},
+			{Name: "customer", Path: "Customer"},
+			{Name: "currency", Path: "Total/@currency"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	table, err := tablifier.Table(context.Background(), []byte(xmlTestInput))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedRows := [][]string{{"1", "Alan", "EUR"}, {"2", "Tim", ""}}
+	if rows := table.StringSlices(); !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("expected rows %+v, got %+v", expectedRows, rows)
+	}
+
+	tablifier, _ = NewXMLRowsTablifier(XMLRowsTablifierConfig{Rows: "/Envelope/Body/Orders/Order"})
+	if table, err = tablifier.Table(context.Background(), []byte(xmlTestInput)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedHeader := []string{"id", "Customer", "Total"}
+	if header := table.Header(); !reflect.DeepEqual(header, expectedHeader) {
+		t.Errorf("expected header %+v, got %+v", expectedHeader, header)
+	}
+}
+
+func TestXMLRemapperCharset(t *testing.T) {
+	remapper := NewXMLRemapper(XMLRemapperConfig{})
+	tests := []struct {
+		input    []byte
+		expected string
+	}{
+		{
+			input:    []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><City>M\xfcnchen</City>"),
+			expected: `{"City":"München"}`,
+		},
+		{
+			input:    []byte("<?xml version=\"1.0\" encoding=\"windows-1251\"?><City>\xcc\xee\xf1\xea\xe2\xe0</City>"),
+			expected: `{"City":"Москва"}`,
+		},
+	}
+	for i, tt := range tests {
+		out, err := remapper.Remap(context.Background(), tt.input)
+		if err != nil {
+			t.Errorf("test #%d: unexpected error: %s", i, err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("test #%d: expected %s, got %s", i, tt.expected, out)
+		}
+	}
+	if _, err := remapper.Remap(context.Background(), []byte(`<?xml version="1.0" encoding="unknown"?><a/>`)); err == nil {
+		t.Error("expected to return error for an unknown charset")
+	}
+}
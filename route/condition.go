@@ -0,0 +1,235 @@
+package route
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Conditions restrict the routes to the requests with the given host, headers and query parameters.
+// The host pattern consists of labels separated by dots, "*" matches any label and ":name" matches any label
+// and makes it the named parameter e.g. "*.example.com" or ":tenant.example.com", the port is ignored.
+// Headers and Query map the names to the patterns of the values:
+//
+//	"" or "*"  - the header or the query parameter must be present
+//	":name"    - the same and the value becomes the named parameter
+//	"prefix*"  - the value must start with the prefix, a suffix may follow "*" as well e.g. "*-eu"
+//	"value"    - the value must be equal to the given one
+type Conditions struct {
+	Host    string
+	Headers map[string]string
+	Query   map[string]string
+}
+
+// key returns the string which is equal for the equal conditions
+func (c Conditions) key() string {
+	key := new(strings.Builder)
+	key.WriteString(c.Host)
+	headers := make(map[string]string, len(c.Headers))
+	for name, pattern := range c.Headers {
+		headers[http.CanonicalHeaderKey(name)] = pattern
+	}
+	for _, values := range []map[string]string{headers, c.Query} {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		key.WriteByte('\n')
+		for _, name := range names {
+			fmt.Fprintf(key, "%q=%q;", name, values[name])
+		}
+	}
+	return key.String()
+}
+
+// RequestConditions checks the request against the conditions
+type RequestConditions struct {
+	host    []valuePattern
+	headers map[string]valuePattern
+	query   map[string]valuePattern
+}
+
+type valuePattern struct {
+	any    bool
+	param  string
+	prefix string
+	suffix string
+	star   bool
+}
+
+func NewRequestConditions(conditions Conditions) (*RequestConditions, error) {
+	c := &RequestConditions{
+		headers: make(map[string]valuePattern, len(conditions.Headers)),
+		query:   make(map[string]valuePattern, len(conditions.Query)),
+	}
+	if conditions.Host != "" {
+		for _, label := range strings.Split(conditions.Host, ".") {
+			if label == "" {
+				return nil, fmt.Errorf("host pattern %s has an empty label", conditions.Host)
+			}
+			// host names are case-insensitive, unlike the names of the parameters
+			pattern := parseValuePattern(label)
+			pattern.prefix, pattern.suffix = strings.ToLower(pattern.prefix), strings.ToLower(pattern.suffix)
+			c.host = append(c.host, pattern)
+		}
+	}
+	for name, pattern := range conditions.Headers {
+		c.headers[http.CanonicalHeaderKey(name)] = parseValuePattern(pattern)
+	}
+	for name, pattern := range conditions.Query {
+		c.query[name] = parseValuePattern(pattern)
+	}
+	return c, nil
+}
+
+// Match reports whether the request satisfies the conditions and returns the named parameters of the patterns
+func (c *RequestConditions) Match(r *http.Request) (NamedPathParameters, bool) {
+	params := make(NamedPathParameters)
+	if len(c.host) > 0 {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		labels := strings.Split(strings.ToLower(strings.TrimSuffix(host, ".")), ".")
+		if len(labels) != len(c.host) {
+			return nil, false
+		}
+		for i, label := range labels {
+			if label == "" || !c.host[i].match(label, params) {
+				return nil, false
+			}
+		}
+	}
+	for name, pattern := range c.headers {
+		values := r.Header.Values(name)
+		if len(values) == 0 || !pattern.match(strings.Join(values, ", "), params) {
+			return nil, false
+		}
+	}
+	if len(c.query) > 0 {
+		query := r.URL.Query()
+		for name, pattern := range c.query {
+			values, ok := query[name]
+			if !ok || !pattern.match(strings.Join(values, ","), params) {
+				return nil, false
+			}
+		}
+	}
+	return params, true
+}
+
+func parseValuePattern(pattern string) valuePattern {
+	switch {
+	case pattern == "" || pattern == "*":
+		return valuePattern{any: true}
+	case len(pattern) > 1 && pattern[0] == ':':
+		return valuePattern{any: true, param: pattern[1:]}
+	}
+	if star := strings.IndexByte(pattern, '*'); star >= 0 {
+		return valuePattern{star: true, prefix: pattern[:star], suffix: pattern[star+1:]}
+	}
+	return valuePattern{prefix: pattern}
+}
+
+func (p valuePattern) match(value string, params NamedPathParameters) bool {
+	switch {
+	case p.any:
+	case p.star:
+		if len(value) < len(p.prefix)+len(p.suffix) ||
+			!strings.HasPrefix(value, p.prefix) ||
+			!strings.HasSuffix(value, p.suffix) {
+			return false
+		}
+	case value != p.prefix:
+		return false
+	}
+	if p.param != "" {
+		params[p.param] = value
+	}
+	return true
+}
+
+// ConditionalRouter routes the requests which satisfy the conditions of the route groups
+// to the routes of the groups and other requests to the routes without conditions.
+// The groups are checked in the order of creation, a group handles the request if its conditions
+// are satisfied and it has a route for the method and the path, otherwise the next group is checked
+// and then the routes without conditions. If the given router is a TreeRouter, the methods of the routes
+// of the satisfied groups are taken into account when the request results in 405 Method Not Allowed.
+// The named parameters of the conditions are passed to the handlers along with the path parameters
+type ConditionalRouter struct {
+	Router
+	groups []*routeGroup
+}
+
+type routeGroup struct {
+	key        string
+	conditions *RequestConditions
+	router     *TreeRouter
+}
+
+// NewConditionalRouter creates the router, the given router handles the routes without conditions
+func NewConditionalRouter(router Router) *ConditionalRouter {
+	return &ConditionalRouter{Router: router}
+}
+
+// Group returns the router of the group of routes which handle the requests satisfying the conditions only,
+// the same conditions result in the same group
+func (r *ConditionalRouter) Group(conditions Conditions) (Router, error) {
+	key := conditions.key()
+	for _, group := range r.groups {
+		if group.key == key {
+			return group.router, nil
+		}
+	}
+	c, err := NewRequestConditions(conditions)
+	if err != nil {
+		return nil, err
+	}
+	group := &routeGroup{key: key, conditions: c, router: NewTreeRouter()}
+	r.groups = append(r.groups, group)
+	return group.router, nil
+}
+
+// Match returns the handler which checks the conditions of the groups against the request it serves
+func (r *ConditionalRouter) Match(method, path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var satisfied []*TreeRouter
+		for _, group := range r.groups {
+			params, ok := group.conditions.Match(req)
+			if !ok {
+				continue
+			}
+			if h := group.router.routeHandler(method, path); h != nil {
+				h.ServeHTTP(w, req.WithContext(ContextWithParameters(req.Context(), params)))
+				return
+			}
+			satisfied = append(satisfied, group.router)
+		}
+		base, ok := r.Router.(*TreeRouter)
+		if !ok {
+			r.Router.Match(method, path).ServeHTTP(w, req)
+			return
+		}
+		if h := base.routeHandler(method, path); h != nil {
+			h.ServeHTTP(w, req)
+			return
+		}
+		routers := append(satisfied, base)
+		allow := allowedMethodsOf(func(method string) bool {
+			for _, router := range routers {
+				if router.match(method, path) != nil {
+					return true
+				}
+			}
+			return false
+		})
+		notMatchedHandler(method, allow, base.notFoundHandler, base.methodNotAllowedHandler).ServeHTTP(w, req)
+	})
+}
+
+func (r *ConditionalRouter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	r.Match(request.Method, request.URL.Path).ServeHTTP(writer, request)
+}
@@ -0,0 +1,179 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRequestConditions(t *testing.T) {
+	tests := []struct {
+		conditions Conditions
+		host       string
+		target     string
+		headers    map[string]string
+		match      bool
+		params     NamedPathParameters
+	}{
+		{
+			conditions: Conditions{Host: "*.example.com"},
+			host:       "acme.example.com:8080",
+			target:     "/",
+			match:      true,
+			params:     NamedPathParameters{},
+		},
+		{
+			conditions: Conditions{Host: "*.example.com"},
+			host:       "example.com",
+			target:     "/",
+		},
+		{
+			conditions: Conditions{Host: ":tenant.Example.com"},
+			host:       "ACME.example.com",
+			target:     "/",
+			match:      true,
+			params:     NamedPathParameters{"tenant": "acme"},
+		},
+		{
+			conditions: Conditions{Host: ":tenantID.*.Example.COM"},
+			host:       "Acme.eu.example.com",
+			target:     "/",
+			match:      true,
+			params:     NamedPathParameters{"tenantID": "acme"},
+		},
+		{
+			conditions: Conditions{Headers: map[string]string{"x-tenant": ":tenant", "X-Region": "eu-*"}},
+			host:       "example.com",
+			target:     "/",
+			headers:    map[string]string{"X-Tenant": "acme", "X-Region": "eu-west"},
+			match:      true,
+			params:     NamedPathParameters{"tenant": "acme"},
+		},
+		{
+			conditions: Conditions{Headers: map[string]string{"X-Region": "eu-*"}},
+			host:       "example.com",
+			target:     "/",
+			headers:    map[string]string{"X-Region": "us-east"},
+		},
+		{
+			conditions: Conditions{Headers: map[string]string{"X-Debug": ""}},
+			host:       "example.com",
+			target:     "/",
+		},
+		{
+			conditions: Conditions{Query: map[string]string{"preview": "", "tenant": ":tenant"}},
+			host:       "example.com",
+			target:     "/?preview&tenant=acme",
+			match:      true,
+			params:     NamedPathParameters{"tenant": "acme"},
+		},
+		{
+			conditions: Conditions{Query: map[string]string{"version": "2"}},
+			host:       "example.com",
+			target:     "/?version=1",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %+v,", i, tt.conditions)
+		conditions, err := NewRequestConditions(tt.conditions)
+		if err != nil {
+			t.Fatalf("%s unexpected error: %s", meta, err)
+		}
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Host = tt.host
+		for name, value := range tt.headers {
+			r.Header.Set(name, value)
+		}
+		params, match := conditions.Match(r)
+		if match != tt.match {
+			t.Errorf("%s expected match to be %t, got %t", meta, tt.match, match)
+		}
+		if match && !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("%s expected parameters %+v, got %+v", meta, tt.params, params)
+		}
+	}
+	if _, err := NewRequestConditions(Conditions{Host: "*..example.com"}); err == nil {
+		t.Errorf("expected to return error for the host pattern with an empty label")
+	}
+}
+
+func TestConditionalRouter(t *testing.T) {
+	router := NewConditionalRouter(NewTreeRouter())
+	register := func(r Router, template string) {
+		err := r.GET(template, func(w http.ResponseWriter, req *http.Request, p NamedPathParameters) {
+			_, _ = fmt.Fprintf(w, "%s %v", template, p)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	register(router, "/exports/:id")
+	tenants, err := router.Group(Conditions{Host: ":tenant.example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	register(tenants, "/exports/:id")
+	preview, err := router.Group(Conditions{Query: map[string]string{"preview": ""}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	register(preview, "/reports/:id")
+	err = preview.POST("/exports/:id", func(w http.ResponseWriter, req *http.Request, p NamedPathParameters) {
+		_, _ = fmt.Fprintf(w, "POST /exports/:id %v", p)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	same, err := router.Group(Conditions{Host: ":tenant.example.com", Headers: map[string]string{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if same != tenants {
+		t.Errorf("expected the same conditions to result in the same group")
+	}
+
+	tests := []struct {
+		method   string
+		host     string
+		target   string
+		expected string
+		code     int
+		allow    string
+	}{
+		{http.MethodGet, "acme.example.com", "/exports/1", "/exports/:id map[id:1 tenant:acme]", http.StatusOK, ""},
+		{http.MethodHead, "acme.example.com", "/exports/1", "/exports/:id map[id:1 tenant:acme]", http.StatusOK, ""},
+		{http.MethodGet, "example.com", "/exports/1", "/exports/:id map[id:1]", http.StatusOK, ""},
+		{http.MethodGet, "acme.example.com", "/reports/1?preview", "/reports/:id map[id:1]", http.StatusOK, ""},
+		{http.MethodGet, "acme.example.com", "/reports/1", "", http.StatusNotFound, ""},
+		// the preview group has the path for POST only, so the request falls through to the other routes
+		{http.MethodGet, "example.com", "/exports/1?preview", "/exports/:id map[id:1]", http.StatusOK, ""},
+		{http.MethodPost, "example.com", "/exports/1?preview", "POST /exports/:id map[id:1]", http.StatusOK, ""},
+		{http.MethodPost, "example.com", "/exports/1", "", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS"},
+		{http.MethodPut, "example.com", "/reports/1?preview", "", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS"},
+		{http.MethodPut, "example.com", "/exports/1?preview", "", http.StatusMethodNotAllowed, "GET, HEAD, POST, OPTIONS"},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %s %s%s,", i, tt.method, tt.host, tt.target)
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		r.Host = tt.host
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Body.String() != tt.expected && tt.code == http.StatusOK {
+			t.Errorf("%s expected %q, got %q", meta, tt.expected, w.Body.String())
+		}
+		if w.Code != tt.code {
+			t.Errorf("%s expected status %d, got %d", meta, tt.code, w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s expected Allow %q, got %q", meta, tt.allow, allow)
+		}
+
+		w = httptest.NewRecorder()
+		router.Match(tt.method, r.URL.Path).ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s expected Match to result in status %d, got %d", meta, tt.code, w.Code)
+		}
+	}
+}
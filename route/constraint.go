@@ -0,0 +1,51 @@
+package route
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ParameterConstraint checks the value of a named path parameter
+type ParameterConstraint func(value string) bool
+
+// Named constraints which can be used instead of regular expressions e.g. ":id{uuid}"
+var namedConstraints = map[string]ParameterConstraint{
+	"int":  regexpConstraint(regexp.MustCompile(`^[0-9]+$`)),
+	"uuid": regexpConstraint(regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)),
+	"date": func(value string) bool {
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	},
+}
+
+// parseNamedSegment parses the named segment ":name" or ":name{constraint}" where the constraint is
+// one of the named constraints or a regular expression which must match the whole segment
+func parseNamedSegment(segment string) (string, ParameterConstraint, error) {
+	name := segment[1:]
+	open := strings.IndexByte(name, '{')
+	if open < 0 {
+		return name, nil, nil
+	}
+	if !strings.HasSuffix(name, "}") {
+		return "", nil, fmt.Errorf("constraint of the named segment %s must end with '}'", segment)
+	}
+	expr := name[open+1 : len(name)-1]
+	name = name[:open]
+	if name == "" || expr == "" {
+		return "", nil, fmt.Errorf("named segment %s must have the name and the constraint", segment)
+	}
+	if constraint, ok := namedConstraints[expr]; ok {
+		return name, constraint, nil
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid constraint of the named segment %s: %s", segment, err)
+	}
+	return name, regexpConstraint(re), nil
+}
+
+func regexpConstraint(re *regexp.Regexp) ParameterConstraint {
+	return re.MatchString
+}
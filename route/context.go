@@ -0,0 +1,20 @@
+package route
+
+import (
+	"context"
+)
+
+type parametersContextKey struct{}
+
+// ContextWithParameters returns a copy of the context which holds the named path parameters
+func ContextWithParameters(ctx context.Context, params NamedPathParameters) context.Context {
+	return context.WithValue(ctx, parametersContextKey{}, params)
+}
+
+// ParametersFromContext returns the named path parameters of the matched route
+func ParametersFromContext(ctx context.Context) NamedPathParameters {
+	if params, ok := ctx.Value(parametersContextKey{}).(NamedPathParameters); ok {
+		return params
+	}
+	return NamedPathParameters{}
+}
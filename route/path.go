@@ -2,6 +2,7 @@ package route
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -21,10 +22,11 @@ type WildCardPathMatcher struct {
 	template      []byte
 	s             string
 	namedSegments map[string]int
+	constraints   map[string]ParameterConstraint
 }
 
 func NewWildCardMatcher(template string) (PathMatcher, error) {
-	template, segments, err := retrieveNamedSegments(template)
+	template, segments, constraints, err := retrieveNamedSegments(template)
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +38,7 @@ func NewWildCardMatcher(template string) (PathMatcher, error) {
 		template:      tplBytes,
 		s:             template,
 		namedSegments: segments,
+		constraints:   constraints,
 	}, nil
 }
 
@@ -50,12 +53,26 @@ func NewWildCardMatcher(template string) (PathMatcher, error) {
 // in segment 2 and "id" in segment 3
 // The template path may also include "or" logical expressions such as "/this|or-this"
 // which could be combined with the wildcards e.g. "/v1/service-*|resource/**"
+// Named segments may be constrained e.g. ":id{[0-9]+}", ":id{uuid}", the path does not match
+// if the segment does not satisfy the constraint
 func (m *WildCardPathMatcher) Match(path string) bool {
-	return m.match(path)
+	if !m.match(path) {
+		return false
+	}
+	if len(m.constraints) == 0 {
+		return true
+	}
+	parameters := retrieveNamedParameters(m.namedSegments, path)
+	for name, constraint := range m.constraints {
+		if !constraint(parameters[name]) {
+			return false
+		}
+	}
+	return true
 }
 
 func (m *WildCardPathMatcher) RetrieveParameters(path string) NamedPathParameters {
-	if !m.match(path) {
+	if !m.Match(path) {
 		return NamedPathParameters{}
 	}
 	return retrieveNamedParameters(m.namedSegments, path)
@@ -170,6 +187,22 @@ func (m *WildCardPathMatcher) match(path string) bool {
 	return len(tpl) <= j
 }
 
+// PathParameterNames returns the names of the named segments of the path template in the order they appear
+func PathParameterNames(template string) ([]string, error) {
+	_, segments, _, err := retrieveNamedSegments(template)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(segments))
+	for name := range segments {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return segments[names[i]] < segments[names[j]]
+	})
+	return names, nil
+}
+
 func retrieveNamedParameters(segments map[string]int, path string) NamedPathParameters {
 	parameters := make(NamedPathParameters)
 	if len(segments) == 0 {
@@ -184,8 +217,9 @@ func retrieveNamedParameters(segments map[string]int, path string) NamedPathPara
 	return parameters
 }
 
-func retrieveNamedSegments(template string) (string, map[string]int, error) {
+func retrieveNamedSegments(template string) (string, map[string]int, map[string]ParameterConstraint, error) {
 	segments := make(map[string]int)
+	constraints := make(map[string]ParameterConstraint)
 	parts := strings.Split(template, "/")
 	newParts := make([]string, len(parts))
 	twoStars := false
@@ -195,13 +229,20 @@ func retrieveNamedSegments(template string) (string, map[string]int, error) {
 		}
 		if len(part) > 1 && part[0] == ':' {
 			if twoStars {
-				return "", nil, fmt.Errorf("named parameters are not allowed after the '**' entry  %s", template)
+				return "", nil, nil, fmt.Errorf("named parameters are not allowed after the '**' entry  %s", template)
+			}
+			name, constraint, err := parseNamedSegment(part)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			segments[name] = i
+			if constraint != nil {
+				constraints[name] = constraint
 			}
-			segments[part[1:]] = i
 			newParts[i] = "*"
 			continue
 		}
 		newParts[i] = part
 	}
-	return strings.Join(newParts, "/"), segments, nil
+	return strings.Join(newParts, "/"), segments, constraints, nil
 }
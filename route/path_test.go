@@ -32,7 +32,7 @@ var retrieveNamedSegmentTests = []retrieveNamedSegmentTest{
 
 func TestRetrieveNamedSegments(t *testing.T) {
 	for i, tt := range retrieveNamedSegmentTests {
-		outPath, segments, err := retrieveNamedSegments(tt.in)
+		outPath, segments, _, err := retrieveNamedSegments(tt.in)
 		meta := fmt.Sprintf("test #%d: retrieveNamedSegments(%q),", i, tt.in)
 		if err != nil {
 			t.Errorf("%s unexpected error: %s", meta, err)
@@ -114,6 +114,87 @@ var wildcardMatcherTests = []wildcardMatcherTest{
 		expectedMatch:      false,
 		expectedParameters: NamedPathParameters{},
 	},
+	{
+		template:           "/books/:id{[0-9]+}",
+		against:            "/books/123",
+		expectedMatch:      true,
+		expectedParameters: NamedPathParameters{"id": "123"},
+	},
+	{
+		template:           "/books/:id{[0-9]+}",
+		against:            "/books/12a",
+		expectedMatch:      false,
+		expectedParameters: NamedPathParameters{},
+	},
+	{
+		template:           "/books/:year{[0-9]{4}}/:kind{csv|pdf}",
+		against:            "/books/2024/pdf",
+		expectedMatch:      true,
+		expectedParameters: NamedPathParameters{"year": "2024", "kind": "pdf"},
+	},
+	{
+		template:           "/books/:year{[0-9]{4}}/:kind{csv|pdf}",
+		against:            "/books/2024/pdfx",
+		expectedMatch:      false,
+		expectedParameters: NamedPathParameters{},
+	},
+	{
+		template:           "/accounts/:id{uuid}/*",
+		against:            "/accounts/0b7d5c2e-8f1a-4e6b-9c3d-2a1f0e9d8c7b/books",
+		expectedMatch:      true,
+		expectedParameters: NamedPathParameters{"id": "0b7d5c2e-8f1a-4e6b-9c3d-2a1f0e9d8c7b"},
+	},
+	{
+		template:           "/accounts/:id{uuid}/*",
+		against:            "/accounts/0b7d5c2e/books",
+		expectedMatch:      false,
+		expectedParameters: NamedPathParameters{},
+	},
+	{
+		template:           "/reports/:from{date}",
+		against:            "/reports/2024-02-29",
+		expectedMatch:      true,
+		expectedParameters: NamedPathParameters{"from": "2024-02-29"},
+	},
+	{
+		template:           "/reports/:from{date}",
+		against:            "/reports/2023-02-29",
+		expectedMatch:      false,
+		expectedParameters: NamedPathParameters{},
+	},
+}
+
+func TestPathParameterNames(t *testing.T) {
+	tests := []struct {
+		template string
+		expected []string
+	}{
+		{"/accounts", []string{}},
+		{"/accounts/:id", []string{"id"}},
+		{"/tenants/:tenant/accounts/:id{[0-9]+}/*", []string{"tenant", "id"}},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: PathParameterNames(%q),", i, tt.template)
+		names, err := PathParameterNames(tt.template)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("%s expected %v, got %v", meta, tt.expected, names)
+		}
+	}
+	if _, err := PathParameterNames("/books/:id{[0-9]+"); err == nil {
+		t.Errorf("expected to return error for the invalid constraint")
+	}
+}
+
+func TestWildCardMatcherInvalidConstraint(t *testing.T) {
+	for i, template := range []string{"/books/:id{[0-9]+", "/books/:id{}", "/books/:{int}", "/books/:id{[0-9}"} {
+		if _, err := NewWildCardMatcher(template); err == nil {
+			t.Errorf("test #%d: NewWildCardMatcher(%q) expected to return error", i, template)
+		}
+	}
 }
 
 func TestWildCardMatcher(t *testing.T) {
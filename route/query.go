@@ -0,0 +1,63 @@
+package route
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// QueryRules rewrite the query string of the request, the rules are applied in the following order:
+// client parameters which are not allowed or dropped are removed, the remaining ones are renamed,
+// then the parameters to set are added overriding the existing values
+type QueryRules struct {
+	// Allow lists the client parameters which are passed through, all parameters are passed if empty
+	Allow []string
+	// Drop lists the client parameters which are removed
+	Drop []string
+	// Rename maps client parameter names to the new names, a renamed parameter overrides
+	// the client parameter with the same name
+	Rename map[string]string
+	// Set maps parameter names to the templates of their values rendered with the named path parameters
+	Set map[string]PathTemplate
+}
+
+// Apply returns the rewritten query
+func (q *QueryRules) Apply(query url.Values, params NamedPathParameters) url.Values {
+	allowed := make(map[string]bool, len(q.Allow))
+	for _, name := range q.Allow {
+		allowed[name] = true
+	}
+	dropped := make(map[string]bool, len(q.Drop))
+	for _, name := range q.Drop {
+		dropped[name] = true
+	}
+
+	result := make(url.Values, len(query)+len(q.Set))
+	renamed := make(url.Values)
+	for name, values := range query {
+		if dropped[name] || (len(allowed) > 0 && !allowed[name]) {
+			continue
+		}
+		if newName, ok := q.Rename[name]; ok {
+			renamed[newName] = values
+			continue
+		}
+		result[name] = values
+	}
+	for name, values := range renamed {
+		result[name] = values
+	}
+	for name, template := range q.Set {
+		result.Set(name, template.Render(params))
+	}
+	return result
+}
+
+// QueryRewrite rewrites the query string of the request according to the rules,
+// the named path parameters are taken from the request context
+func QueryRewrite(rules *QueryRules, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := rules.Apply(r.URL.Query(), ParametersFromContext(r.Context()))
+		r.URL.RawQuery = query.Encode()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,78 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+var queryRulesTests = []struct {
+	rules *QueryRules
+	in    string
+	out   string
+}{
+	{
+		rules: &QueryRules{},
+		in:    "a=1&a=2&b=3",
+		out:   "a=1&a=2&b=3",
+	},
+	{
+		rules: &QueryRules{Allow: []string{"from", "to"}},
+		in:    "from=1&to=2&debug=1",
+		out:   "from=1&to=2",
+	},
+	{
+		rules: &QueryRules{Drop: []string{"debug"}},
+		in:    "from=1&debug=1",
+		out:   "from=1",
+	},
+	{
+		rules: &QueryRules{Allow: []string{"from", "debug"}, Drop: []string{"debug"}},
+		in:    "from=1&to=2&debug=1",
+		out:   "from=1",
+	},
+	{
+		rules: &QueryRules{Rename: map[string]string{"from": "start", "to": "end"}},
+		in:    "from=1&to=2&start=0",
+		out:   "end=2&start=1",
+	},
+	{
+		rules: &QueryRules{
+			Rename: map[string]string{"from": "start"},
+			Set:    map[string]PathTemplate{"account": ColonParamsReplaceTemplate("acc-:id"), "from": ColonParamsReplaceTemplate("x")},
+		},
+		in:  "from=1&account=2",
+		out: "account=acc-42&from=x&start=1",
+	},
+}
+
+func TestQueryRulesApply(t *testing.T) {
+	params := NamedPathParameters{"id": "42"}
+	for i, tt := range queryRulesTests {
+		meta := fmt.Sprintf("test #%d: Apply(%q),", i, tt.in)
+		in, _ := url.ParseQuery(tt.in)
+		out := tt.rules.Apply(in, params).Encode()
+		if out != tt.out {
+			t.Errorf("%s expected %q, got %q", meta, tt.out, out)
+		}
+	}
+}
+
+func TestQueryRewrite(t *testing.T) {
+	rules := &QueryRules{
+		Drop: []string{"debug"},
+		Set:  map[string]PathTemplate{"id": ColonParamsReplaceTemplate(":id")},
+	}
+	var query string
+	handler := QueryRewrite(rules, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/export/42?debug=1&from=2024", nil)
+	r = r.WithContext(ContextWithParameters(r.Context(), NamedPathParameters{"id": "42"}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if expected := "from=2024&id=42"; query != expected {
+		t.Errorf("expected query %q, got %q", expected, query)
+	}
+}
@@ -3,6 +3,7 @@ package route
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type Handler func(w http.ResponseWriter, r *http.Request, params NamedPathParameters)
@@ -20,18 +21,28 @@ type Router interface {
 
 type MatcherFactory func(template string) (PathMatcher, error)
 
+// MethodAny registers the route which handles requests of any method,
+// a route of the request method takes precedence over it
+const MethodAny = "ANY"
+
 type route struct {
 	template string
 	matcher  PathMatcher
 	handler  Handler
 }
 
+// DefaultRouter checks the routes one by one in the order of registration, so the first matching route wins
+// and the time of matching grows with the number of routes. A route is rejected if its template, taken as a path,
+// matches a registered route. Only TreeRouter selects the most specific route regardless of the order
+// of registration and detects the conflicts of the templates which differ in the names of the parameters only.
 type DefaultRouter struct {
-	createMatcher   MatcherFactory
-	routes          map[string][]*route
-	notFoundHandler http.Handler
+	createMatcher           MatcherFactory
+	routes                  map[string][]*route
+	notFoundHandler         http.Handler
+	methodNotAllowedHandler http.Handler
 }
 
+// NewDefaultRouter creates the router which matches the paths by means of the matchers created by the factory.
 func NewDefaultRouter(
 	matcherFactory MatcherFactory,
 ) *DefaultRouter {
@@ -39,16 +50,31 @@ func NewDefaultRouter(
 		matcherFactory,
 		make(map[string][]*route),
 		http.HandlerFunc(notFoundHandler),
+		http.HandlerFunc(methodNotAllowedHandler),
 	}
 }
 
+// Match returns the handler of the route which matches the method and the path.
+// HEAD requests are handled by GET routes unless HEAD routes are registered, routes registered with MethodAny
+// handle the methods which have no matching routes. If the path matches routes of other methods only
+// OPTIONS requests result in 204 No Content and other requests are passed to the method not allowed handler,
+// both with the Allow header listing the methods of the path
 func (r *DefaultRouter) Match(method, path string) http.Handler {
 	rt := r.match(method, path)
+	if rt == nil && method == http.MethodHead {
+		rt = r.match(http.MethodGet, path)
+	}
+	if rt == nil {
+		rt = r.match(MethodAny, path)
+	}
 	if rt == nil {
-		return r.notFoundHandler
+		allow := allowedMethodsOf(func(method string) bool {
+			return r.match(method, path) != nil
+		})
+		return notMatchedHandler(method, allow, r.notFoundHandler, r.methodNotAllowedHandler)
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		rt.handler(w, req, rt.matcher.RetrieveParameters(req.URL.Path))
+		handleRoute(rt.handler, w, req, rt.matcher.RetrieveParameters(req.URL.Path))
 	})
 }
 
@@ -74,22 +100,61 @@ func (r *DefaultRouter) PUT(path string, h Handler) error {
 
 var allowedMethods = []string{
 	http.MethodGet,
+	http.MethodHead,
 	http.MethodPost,
 	http.MethodPut,
 	http.MethodPatch,
 	http.MethodDelete,
+	http.MethodOptions,
 }
 
-func (r *DefaultRouter) Handle(method, path string, handler Handler) error {
-	allowed := false
+func checkMethod(method string) error {
+	if method == MethodAny {
+		return nil
+	}
 	for _, allowedMethod := range allowedMethods {
 		if allowedMethod == method {
-			allowed = true
-			break
+			return nil
+		}
+	}
+	return fmt.Errorf("method %s could not be processed", method)
+}
+
+// allowedMethodsOf returns the methods which have routes matching the path, HEAD is added along with GET
+// and OPTIONS is added if there is any method
+func allowedMethodsOf(matches func(method string) bool) []string {
+	var allow []string
+	for _, method := range allowedMethods {
+		switch {
+		case matches(method):
+		case method == http.MethodHead && len(allow) > 0 && allow[0] == http.MethodGet:
+		case method == http.MethodOptions && len(allow) > 0:
+		default:
+			continue
 		}
+		allow = append(allow, method)
 	}
-	if !allowed {
-		return fmt.Errorf("method %s could not be processed", method)
+	return allow
+}
+
+// notMatchedHandler returns the handler of the request which does not match any route of its method
+func notMatchedHandler(method string, allow []string, notFound, methodNotAllowed http.Handler) http.Handler {
+	if len(allow) == 0 {
+		return notFound
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		if method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		methodNotAllowed.ServeHTTP(w, req)
+	})
+}
+
+func (r *DefaultRouter) Handle(method, path string, handler Handler) error {
+	if err := checkMethod(method); err != nil {
+		return err
 	}
 
 	if rt := r.match(method, path); rt != nil {
@@ -118,6 +183,12 @@ func (r *DefaultRouter) SetNotFoundHandler(h http.Handler) {
 	r.notFoundHandler = h
 }
 
+// SetMethodNotAllowedHandler sets the handler of the requests whose path matches routes of other methods only,
+// the Allow header is set before the handler is called
+func (r *DefaultRouter) SetMethodNotAllowedHandler(h http.Handler) {
+	r.methodNotAllowedHandler = h
+}
+
 func (r *DefaultRouter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	r.Match(request.Method, request.URL.Path).ServeHTTP(writer, request)
 }
@@ -131,6 +202,26 @@ func (r *DefaultRouter) match(method, path string) *route {
 	return nil
 }
 
+// handleRoute calls the handler with the path parameters added to the parameters which are already
+// in the request context e.g. the parameters of the route group conditions
+func handleRoute(h Handler, w http.ResponseWriter, req *http.Request, params NamedPathParameters) {
+	if inherited := ParametersFromContext(req.Context()); len(inherited) > 0 {
+		merged := make(NamedPathParameters, len(inherited)+len(params))
+		for name, value := range inherited {
+			merged[name] = value
+		}
+		for name, value := range params {
+			merged[name] = value
+		}
+		params = merged
+	}
+	h(w, req.WithContext(ContextWithParameters(req.Context(), params)), params)
+}
+
 func notFoundHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusNotFound)
 }
+
+func methodNotAllowedHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
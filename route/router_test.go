@@ -0,0 +1,104 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultRouterConstraintFallThrough(t *testing.T) {
+	router := NewDefaultRouter(NewWildCardMatcher)
+	for _, template := range []string{"/items/:id{int}", "/items/:id{uuid}", "/items/:name"} {
+		template := template
+		err := router.GET(template, func(w http.ResponseWriter, r *http.Request, p NamedPathParameters) {
+			_, _ = fmt.Fprintf(w, "%s %v", template, p)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/items/42", "/items/:id{int} map[id:42]"},
+		{"/items/0b7d5c2e-8f1a-4e6b-9c3d-2a1f0e9d8c7b", "/items/:id{uuid} map[id:0b7d5c2e-8f1a-4e6b-9c3d-2a1f0e9d8c7b]"},
+		{"/items/abc", "/items/:name map[name:abc]"},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: GET %s,", i, tt.path)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Body.String() != tt.expected {
+			t.Errorf("%s expected %q, got %q", meta, tt.expected, w.Body.String())
+		}
+	}
+}
+
+func TestRouterMethods(t *testing.T) {
+	routers := map[string]interface {
+		Router
+		SetMethodNotAllowedHandler(h http.Handler)
+	}{
+		"default": NewDefaultRouter(NewWildCardMatcher),
+		"tree":    NewTreeRouter(),
+	}
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		allow    string
+		expected string
+	}{
+		{http.MethodGet, "/books", http.StatusOK, "", "GET /books"},
+		{http.MethodHead, "/books", http.StatusOK, "", "GET /books"},
+		{http.MethodPost, "/books", http.StatusOK, "", "POST /books"},
+		{http.MethodDelete, "/books", http.StatusMethodNotAllowed, "GET, HEAD, POST, OPTIONS", "not allowed"},
+		{http.MethodOptions, "/books", http.StatusNoContent, "GET, HEAD, POST, OPTIONS", ""},
+		{http.MethodHead, "/authors", http.StatusOK, "", "HEAD /authors"},
+		{http.MethodPut, "/authors", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS", "not allowed"},
+		{http.MethodDelete, "/proxy/books", http.StatusOK, "", "ANY /proxy/**"},
+		{http.MethodGet, "/proxy/books", http.StatusOK, "", "GET /proxy/books"},
+		{http.MethodOptions, "/proxy/books", http.StatusOK, "", "ANY /proxy/**"},
+		{http.MethodGet, "/unknown", http.StatusNotFound, "", ""},
+		{http.MethodOptions, "/unknown", http.StatusNotFound, "", ""},
+	}
+	for name, router := range routers {
+		routes := [][2]string{
+			{http.MethodGet, "/books"},
+			{http.MethodPost, "/books"},
+			{http.MethodGet, "/authors"},
+			{http.MethodHead, "/authors"},
+			{http.MethodGet, "/proxy/books"},
+			{MethodAny, "/proxy/**"},
+		}
+		for _, rt := range routes {
+			rt := rt
+			err := router.Handle(rt[0], rt[1], func(w http.ResponseWriter, r *http.Request, p NamedPathParameters) {
+				_, _ = fmt.Fprintf(w, "%s %s", rt[0], rt[1])
+			})
+			if err != nil {
+				t.Fatalf("%s router: unexpected error: %s", name, err)
+			}
+		}
+		router.SetMethodNotAllowedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_, _ = fmt.Fprint(w, "not allowed")
+		}))
+		for i, tt := range tests {
+			meta := fmt.Sprintf("test #%d: %s router, %s %s,", i, name, tt.method, tt.path)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.code {
+				t.Errorf("%s expected code %d, got %d", meta, tt.code, w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.allow {
+				t.Errorf("%s expected Allow %q, got %q", meta, tt.allow, allow)
+			}
+			if w.Body.String() != tt.expected {
+				t.Errorf("%s expected body %q, got %q", meta, tt.expected, w.Body.String())
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -9,14 +10,27 @@ type PathTemplate interface {
 	Render(params NamedPathParameters) string
 }
 
+// ColonParamsReplaceTemplate replaces ":name" with the value of the named parameter.
+// The longest name wins if several names match at the same position e.g. ":tenant" is not taken
+// for ":t" followed by "enant", the substituted values are not searched for the names again
 type ColonParamsReplaceTemplate string
 
 func (c ColonParamsReplaceTemplate) Render(params NamedPathParameters) string {
-	result := string(c)
-	for name, value := range params {
-		result = strings.ReplaceAll(result, ":"+name, value)
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
 	}
-	return result
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	oldnew := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		oldnew = append(oldnew, ":"+name, params[name])
+	}
+	return strings.NewReplacer(oldnew...).Replace(string(c))
 }
 
 func PathSubstitution(
@@ -0,0 +1,35 @@
+package route
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestColonParamsReplaceTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		params   NamedPathParameters
+		expected string
+	}{
+		{"/accounts/:id", NamedPathParameters{"id": "42"}, "/accounts/42"},
+		{"/accounts/:id/:id", NamedPathParameters{"id": "42", "from": "x"}, "/accounts/42/42"},
+		{"/accounts/:id", NamedPathParameters{}, "/accounts/:id"},
+		{
+			"/tenants/:tenant/:t/exports/:tenantID",
+			NamedPathParameters{"t": "eu", "tenant": "acme", "tenantID": "7"},
+			"/tenants/acme/eu/exports/7",
+		},
+		{"/items/:id-:idx", NamedPathParameters{"id": "1", "idx": "2"}, "/items/1-2"},
+		{"/items/:a/:b", NamedPathParameters{"a": ":b", "b": "2"}, "/items/:b/2"},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %q with %v,", i, tt.template, tt.params)
+		// the parameters are kept in a map, so the rendering is repeated to catch the order dependency
+		for n := 0; n < 20; n++ {
+			if actual := ColonParamsReplaceTemplate(tt.template).Render(tt.params); actual != tt.expected {
+				t.Errorf("%s expected %s, got %s", meta, tt.expected, actual)
+				break
+			}
+		}
+	}
+}
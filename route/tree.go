@@ -0,0 +1,422 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TreeRouter is the Router which keeps the templates of every method in a tree of path segments,
+// so the time of matching depends on the depth of the path rather than on the number of routes.
+// It supports the same templates as the DefaultRouter with the WildCardPathMatcher:
+// "*" and "prefix*" match one segment, "**" and "prefix**" match the rest of the path,
+// "a|b" matches one of the alternatives and ":name" or ":name{constraint}" is the named segment.
+// A "*" followed by a suffix within the segment e.g. "*.csv" matches the segments with the given prefix and suffix.
+//
+// If several routes match the path the most specific one wins, segments are compared from left to right:
+// a static segment beats a named segment with a constraint, which beats a "prefix*" wildcard,
+// which beats a named segment, which beats "*", which beats "prefix**", which beats "**".
+// So "/reports/summary" and "/reports/:id" may be registered in any order. Only the ambiguous templates
+// which are the same up to the names of the named segments are rejected e.g. "/reports/:id" and "/reports/:name",
+// if the routes are equally specific for the path, e.g. "/items/:id{int}" and "/items/:id{[0-9a-f]+}",
+// the route which has been registered first wins
+// Methods are handled in the same way as by the DefaultRouter, see DefaultRouter.Match
+type TreeRouter struct {
+	trees                   map[string]*treeNode
+	routes                  int
+	notFoundHandler         http.Handler
+	methodNotAllowedHandler http.Handler
+}
+
+type treeRoute struct {
+	template string
+	order    int
+	handler  Handler
+}
+
+// Ranks of the template segments, the lower rank is more specific
+const (
+	rankStatic = iota
+	rankConstrainedParameter
+	rankPartialWildcard
+	rankParameter
+	rankWildcard
+	rankPartialCatchAll
+	rankCatchAll
+)
+
+type treeNode struct {
+	static    map[string]*treeNode
+	wildcards []*treeWildcard
+	catchAll  []*treeCatchAll
+	route     *treeRoute
+}
+
+// treeWildcard matches one segment
+type treeWildcard struct {
+	key        string
+	pattern    string
+	rank       int
+	prefix     string
+	suffix     string
+	param      string
+	constraint ParameterConstraint
+	node       *treeNode
+}
+
+// treeCatchAll matches the rest of the path
+type treeCatchAll struct {
+	prefix string
+	rank   int
+	route  *treeRoute
+}
+
+type treeParameter struct {
+	name  string
+	value string
+}
+
+type treeMatch struct {
+	route      *treeRoute
+	ranks      []int
+	parameters NamedPathParameters
+}
+
+func NewTreeRouter() *TreeRouter {
+	return &TreeRouter{
+		trees:                   make(map[string]*treeNode),
+		notFoundHandler:         http.HandlerFunc(notFoundHandler),
+		methodNotAllowedHandler: http.HandlerFunc(methodNotAllowedHandler),
+	}
+}
+
+func (r *TreeRouter) Match(method, path string) http.Handler {
+	if h := r.routeHandler(method, path); h != nil {
+		return h
+	}
+	allow := allowedMethodsOf(func(method string) bool {
+		return r.match(method, path) != nil
+	})
+	return notMatchedHandler(method, allow, r.notFoundHandler, r.methodNotAllowedHandler)
+}
+
+// routeHandler returns the handler of the route which handles the method and the path
+// including the HEAD and MethodAny fallbacks, nil if there is no such route
+func (r *TreeRouter) routeHandler(method, path string) http.Handler {
+	m := r.match(method, path)
+	if m == nil && method == http.MethodHead {
+		m = r.match(http.MethodGet, path)
+	}
+	if m == nil {
+		m = r.match(MethodAny, path)
+	}
+	if m == nil {
+		return nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handleRoute(m.route.handler, w, req, m.parameters)
+	})
+}
+
+func (r *TreeRouter) GET(path string, h Handler) error {
+	return r.Handle(http.MethodGet, path, h)
+}
+
+func (r *TreeRouter) POST(path string, h Handler) error {
+	return r.Handle(http.MethodPost, path, h)
+}
+
+func (r *TreeRouter) DELETE(path string, h Handler) error {
+	return r.Handle(http.MethodDelete, path, h)
+}
+
+func (r *TreeRouter) PATCH(path string, h Handler) error {
+	return r.Handle(http.MethodPatch, path, h)
+}
+
+func (r *TreeRouter) PUT(path string, h Handler) error {
+	return r.Handle(http.MethodPut, path, h)
+}
+
+func (r *TreeRouter) Handle(method, path string, handler Handler) error {
+	if err := checkMethod(method); err != nil {
+		return err
+	}
+
+	segments, err := parseTreeTemplate(path)
+	if err != nil {
+		return err
+	}
+	root := r.trees[method]
+	if root == nil {
+		root = &treeNode{}
+	}
+	rt := &treeRoute{template: path, order: r.routes, handler: handler}
+	// the template is checked before it is added, so the tree is not changed in case of the error
+	if existing := root.find(segments); existing != nil {
+		return fmt.Errorf(
+			"handler is already registered for path %s %s, it is ambiguous with the path %s",
+			method,
+			path,
+			existing.template,
+		)
+	}
+	root.insert(segments, rt)
+	r.trees[method] = root
+	r.routes++
+
+	return nil
+}
+
+func (r *TreeRouter) SetNotFoundHandler(h http.Handler) {
+	r.notFoundHandler = h
+}
+
+// SetMethodNotAllowedHandler sets the handler of the requests whose path matches routes of other methods only,
+// the Allow header is set before the handler is called
+func (r *TreeRouter) SetMethodNotAllowedHandler(h http.Handler) {
+	r.methodNotAllowedHandler = h
+}
+
+func (r *TreeRouter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	r.Match(request.Method, request.URL.Path).ServeHTTP(writer, request)
+}
+
+func (r *TreeRouter) match(method, path string) *treeMatch {
+	root := r.trees[method]
+	if root == nil || path == "" {
+		return nil
+	}
+	m := &treeMatch{}
+	root.lookup(splitPath(path), nil, nil, m)
+	if m.route == nil {
+		return nil
+	}
+	return m
+}
+
+// lookup looks for the most specific route among all routes matching the segments,
+// ranks are the ranks of the template segments which have matched the path so far
+func (n *treeNode) lookup(segments []string, ranks []int, parameters []treeParameter, m *treeMatch) {
+	if len(segments) == 0 {
+		if n.route != nil {
+			m.offer(n.route, ranks, parameters)
+		}
+		return
+	}
+	segment := segments[0]
+	if child := n.static[segment]; child != nil {
+		child.lookup(segments[1:], append(ranks, rankStatic), parameters, m)
+	}
+	for _, w := range n.wildcards {
+		if !w.match(segment) {
+			continue
+		}
+		if w.param != "" {
+			w.node.lookup(segments[1:], append(ranks, w.rank), append(parameters, treeParameter{w.param, segment}), m)
+			continue
+		}
+		w.node.lookup(segments[1:], append(ranks, w.rank), parameters, m)
+	}
+	for _, c := range n.catchAll {
+		if strings.HasPrefix(segment, c.prefix) {
+			m.offer(c.route, append(ranks, c.rank), parameters)
+		}
+	}
+}
+
+// offer replaces the matched route if the given one is more specific
+// or equally specific and has been registered earlier
+func (m *treeMatch) offer(rt *treeRoute, ranks []int, parameters []treeParameter) {
+	if m.route != nil {
+		if c := compareRanks(ranks, m.ranks); c > 0 || (c == 0 && m.route.order <= rt.order) {
+			return
+		}
+	}
+	m.route = rt
+	m.ranks = append(m.ranks[:0], ranks...)
+	m.parameters = make(NamedPathParameters, len(parameters))
+	for _, p := range parameters {
+		m.parameters[p.name] = p.value
+	}
+}
+
+// compareRanks compares the ranks of the segments from left to right, the negative result means
+// that a is more specific than b
+func compareRanks(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+func (w *treeWildcard) match(segment string) bool {
+	if segment == "" || len(segment) < len(w.prefix)+len(w.suffix) {
+		return false
+	}
+	if !strings.HasPrefix(segment, w.prefix) || !strings.HasSuffix(segment, w.suffix) {
+		return false
+	}
+	return w.constraint == nil || w.constraint(segment)
+}
+
+// find returns the route registered for the same segments if any, the names of the named segments are ignored
+func (n *treeNode) find(segments [][]treeSegment) *treeRoute {
+	if len(segments) == 0 {
+		return n.route
+	}
+	for _, alternative := range segments[0] {
+		switch {
+		case alternative.catchAll:
+			for _, c := range n.catchAll {
+				if c.prefix == alternative.prefix {
+					return c.route
+				}
+			}
+		case alternative.wildcard:
+			for _, w := range n.wildcards {
+				if w.pattern == alternative.pattern {
+					if rt := w.node.find(segments[1:]); rt != nil {
+						return rt
+					}
+				}
+			}
+		default:
+			if child := n.static[alternative.key]; child != nil {
+				if rt := child.find(segments[1:]); rt != nil {
+					return rt
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (n *treeNode) insert(segments [][]treeSegment, rt *treeRoute) {
+	if len(segments) == 0 {
+		n.route = rt
+		return
+	}
+	for _, alternative := range segments[0] {
+		switch {
+		case alternative.catchAll:
+			n.catchAll = append(n.catchAll, &treeCatchAll{prefix: alternative.prefix, rank: alternative.rank, route: rt})
+		case alternative.wildcard:
+			n.wildcard(alternative).insert(segments[1:], rt)
+		default:
+			if n.static == nil {
+				n.static = make(map[string]*treeNode)
+			}
+			child := n.static[alternative.key]
+			if child == nil {
+				child = &treeNode{}
+				n.static[alternative.key] = child
+			}
+			child.insert(segments[1:], rt)
+		}
+	}
+}
+
+func (n *treeNode) wildcard(segment treeSegment) *treeNode {
+	for _, w := range n.wildcards {
+		if w.key == segment.key {
+			return w.node
+		}
+	}
+	w := &treeWildcard{
+		key:        segment.key,
+		pattern:    segment.pattern,
+		rank:       segment.rank,
+		prefix:     segment.prefix,
+		suffix:     segment.suffix,
+		param:      segment.param,
+		constraint: segment.constraint,
+		node:       &treeNode{},
+	}
+	n.wildcards = append(n.wildcards, w)
+	return w.node
+}
+
+// treeSegment is one of the alternatives of the template segment,
+// the pattern is the key without the name of the named segment
+type treeSegment struct {
+	key        string
+	pattern    string
+	rank       int
+	wildcard   bool
+	catchAll   bool
+	prefix     string
+	suffix     string
+	param      string
+	constraint ParameterConstraint
+}
+
+// parseTreeTemplate splits the template into segments, each segment is the list of its alternatives.
+// Segments following "**" are ignored since it matches the rest of the path
+func parseTreeTemplate(template string) ([][]treeSegment, error) {
+	var segments [][]treeSegment
+	for _, part := range splitPath(template) {
+		if len(part) > 1 && part[0] == ':' {
+			name, constraint, err := parseNamedSegment(part)
+			if err != nil {
+				return nil, err
+			}
+			segment := treeSegment{key: part, pattern: ":", rank: rankParameter, wildcard: true, param: name, constraint: constraint}
+			if constraint != nil {
+				segment.pattern = part[len(name)+1:]
+				segment.rank = rankConstrainedParameter
+			}
+			segments = append(segments, []treeSegment{segment})
+			continue
+		}
+		var alternatives []treeSegment
+		catchAll := false
+		for _, alternative := range strings.Split(part, "|") {
+			star := strings.IndexByte(alternative, '*')
+			switch {
+			case star < 0:
+				alternatives = append(alternatives, treeSegment{key: alternative})
+			case strings.HasPrefix(alternative[star:], "**"):
+				catchAll = true
+				rank := rankCatchAll
+				if star > 0 {
+					rank = rankPartialCatchAll
+				}
+				alternatives = append(alternatives, treeSegment{catchAll: true, rank: rank, prefix: alternative[:star]})
+			default:
+				rank := rankWildcard
+				if len(alternative) > 1 {
+					rank = rankPartialWildcard
+				}
+				alternatives = append(alternatives, treeSegment{
+					key:      alternative,
+					pattern:  alternative,
+					rank:     rank,
+					wildcard: true,
+					prefix:   alternative[:star],
+					suffix:   alternative[star+1:],
+				})
+			}
+		}
+		segments = append(segments, alternatives)
+		if catchAll {
+			break
+		}
+	}
+	return segments, nil
+}
+
+// splitPath splits the path into segments, repeated and trailing slashes are ignored,
+// the leading slash results in the empty first segment, so "/a" and "a" are different paths
+func splitPath(path string) []string {
+	parts := strings.Split(path, "/")
+	segments := parts[:0]
+	for i, part := range parts {
+		if part != "" || (i == 0 && len(parts) > 1) {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
@@ -0,0 +1,207 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var treeRouterTests = append([]wildcardMatcherTest{
+	{template: "/x/a*", against: "/x/a", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/*", against: "/x/", expectedMatch: false, expectedParameters: NamedPathParameters{}},
+	{template: "/x/**", against: "/x", expectedMatch: false, expectedParameters: NamedPathParameters{}},
+	{template: "/x/a**", against: "/x/ab/c", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/a*|b/c", against: "/x/azz/c", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/a|b*/c", against: "/x/bzz/c", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/y/**/z", against: "/x/y/q", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/", against: "/x", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/y", against: "//x//y/", expectedMatch: true, expectedParameters: NamedPathParameters{}},
+	{template: "/x/y", against: "/x/y/z", expectedMatch: false, expectedParameters: NamedPathParameters{}},
+	{template: "/x/y", against: "x/y", expectedMatch: false, expectedParameters: NamedPathParameters{}},
+}, wildcardMatcherTests...)
+
+func TestTreeRouter(t *testing.T) {
+	for i, tt := range treeRouterTests {
+		meta := fmt.Sprintf("test #%d: template %q, GET %q,", i, tt.template, tt.against)
+		var parameters NamedPathParameters
+		router := NewTreeRouter()
+		err := router.GET(tt.template, func(w http.ResponseWriter, r *http.Request, p NamedPathParameters) {
+			parameters = p
+		})
+		if err != nil {
+			t.Fatalf("%s unexpected error: %s", meta, err)
+		}
+
+		w := httptest.NewRecorder()
+		router.Match(http.MethodGet, tt.against).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		match := w.Code != http.StatusNotFound
+		if match != tt.expectedMatch {
+			t.Errorf("%s expected match to be %t, got %t", meta, tt.expectedMatch, match)
+		}
+		if match && !reflect.DeepEqual(parameters, tt.expectedParameters) {
+			t.Errorf("%s expected parameters %+v, got %+v", meta, tt.expectedParameters, parameters)
+		}
+
+		matcher, err := NewWildCardMatcher(tt.template)
+		if err != nil {
+			t.Fatalf("%s unexpected error: %s", meta, err)
+		}
+		if matcher.Match(tt.against) != match {
+			t.Errorf("%s expected to match as the WildCardPathMatcher", meta)
+		}
+	}
+}
+
+func TestTreeRouterMostSpecificWins(t *testing.T) {
+	router := NewTreeRouter()
+	templates := []string{
+		"/a/*/c",
+		"/a/b/*",
+		"/items/:name",
+		"/items/:id{int}",
+		"/items/:id{[0-9a-f]+}",
+		"/items/:id{uuid}",
+		"/reports/:id",
+		"/reports/summary",
+		"/files/**",
+		"/files/report-**",
+		"/files/*",
+		"/files/:name/:page",
+		"/files/*.csv",
+	}
+	for _, template := range templates {
+		template := template
+		err := router.GET(template, func(w http.ResponseWriter, r *http.Request, p NamedPathParameters) {
+			_, _ = fmt.Fprintf(w, "%s %v", template, p)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/a/b/c", "/a/b/* map[]"},
+		{"/a/x/c", "/a/*/c map[]"},
+		{"/items/42", "/items/:id{int} map[id:42]"},
+		{"/items/0b7d5c2e-8f1a-4e6b-9c3d-2a1f0e9d8c7b", "/items/:id{uuid} map[id:0b7d5c2e-8f1a-4e6b-9c3d-2a1f0e9d8c7b]"},
+		{"/items/abc", "/items/:id{[0-9a-f]+} map[id:abc]"},
+		{"/items/xyz", "/items/:name map[name:xyz]"},
+		{"/reports/summary", "/reports/summary map[]"},
+		{"/reports/42", "/reports/:id map[id:42]"},
+		{"/files/a.csv", "/files/*.csv map[]"},
+		{"/files/a.pdf", "/files/* map[]"},
+		{"/files/a.csv/2", "/files/:name/:page map[name:a.csv page:2]"},
+		{"/files/report-1/2/3", "/files/report-** map[]"},
+		{"/files/a/2/3", "/files/** map[]"},
+		{"/unknown", ""},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: GET %s,", i, tt.path)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Body.String() != tt.expected {
+			t.Errorf("%s expected %q, got %q", meta, tt.expected, w.Body.String())
+		}
+	}
+}
+
+func TestTreeRouterConflicts(t *testing.T) {
+	tests := []struct {
+		registered []string
+		template   string
+		conflict   bool
+	}{
+		{[]string{"/a/*"}, "/a/b", false},
+		{[]string{"/a/b"}, "/a/*", false},
+		{[]string{"/a/**"}, "/a/b/c", false},
+		{[]string{"/a/*"}, "/a/:id", false},
+		{[]string{"/a/*"}, "/a/*", true},
+		{[]string{"/a/**"}, "/a/**", true},
+		{[]string{"/a/:id"}, "/a/:name", true},
+		{[]string{"/a/:id/b"}, "/a/:name/c", false},
+		{[]string{"/a/:id{int}"}, "/a/:name{int}", true},
+		{[]string{"/a|b/c"}, "/b/c", true},
+		{[]string{"/a|b/c"}, "/b|d/c", true},
+		{[]string{"/a/:id{int}"}, "/a/:id{int}", true},
+		{[]string{"/a/:id{int}"}, "/a/:id{uuid}", false},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: GET %s after %v,", i, tt.template, tt.registered)
+		router := NewTreeRouter()
+		for _, template := range tt.registered {
+			if err := router.GET(template, nil); err != nil {
+				t.Fatalf("%s unexpected error: %s", meta, err)
+			}
+		}
+		err := router.GET(tt.template, nil)
+		if tt.conflict && err == nil {
+			t.Errorf("%s expected to return error", meta)
+		}
+		if !tt.conflict && err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+		}
+	}
+	if err := NewTreeRouter().Handle("TRACE", "/a", nil); err == nil {
+		t.Errorf("expected to return error for the method TRACE")
+	}
+}
+
+func benchmarkTemplates(n int) []string {
+	templates := make([]string, n)
+	for i := range templates {
+		templates[i] = fmt.Sprintf("/api/v1/exports/report-%d/:id/*", i)
+	}
+	return templates
+}
+
+func registerTemplates(b *testing.B, router Router, templates []string) {
+	handler := func(w http.ResponseWriter, r *http.Request, p NamedPathParameters) {}
+	for _, template := range templates {
+		if err := router.GET(template, handler); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRouterMatch(b *testing.B) {
+	routers := []struct {
+		name   string
+		create func() Router
+	}{
+		{"default", func() Router { return NewDefaultRouter(NewWildCardMatcher) }},
+		{"tree", func() Router { return NewTreeRouter() }},
+	}
+	for _, n := range []int{10, 100, 1000} {
+		templates := benchmarkTemplates(n)
+		path := fmt.Sprintf("/api/v1/exports/report-%d/42/csv", n-1)
+		for _, r := range routers {
+			router := r.create()
+			registerTemplates(b, router, templates)
+			b.Run(fmt.Sprintf("%s/%d", r.name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					router.Match(http.MethodGet, path)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRouterRegister(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		templates := benchmarkTemplates(n)
+		b.Run(fmt.Sprintf("default/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				registerTemplates(b, NewDefaultRouter(NewWildCardMatcher), templates)
+			}
+		})
+		b.Run(fmt.Sprintf("tree/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				registerTemplates(b, NewTreeRouter(), templates)
+			}
+		})
+	}
+}
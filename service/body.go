@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/manipulation"
+)
+
+// DefaultMaxRequestBodySize is the default limit of the request body size which is transformed
+const DefaultMaxRequestBodySize = 32 << 20
+
+// TransformRequestBody replaces the body of the request with the JSON produced by the transformer,
+// requests without a body are left as is. If the body is larger than maxSize the error
+// is 413 Request Entity Too Large, if it cannot be transformed the error is 400 Bad Request.
+// Exceeding the execution limits of the transformer or the end of the request context are not
+// considered client errors. The details of the error are logged only, the client gets the generic message
+func TransformRequestBody(r *http.Request, transformer *manipulation.RequestBodyTransformer, maxSize int64) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxRequestBodySize
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	_ = r.Body.Close()
+	if err != nil {
+		return errors.Wrap(err, "TransformRequestBody: cannot read request body")
+	}
+	if int64(len(body)) > maxSize {
+		return errors.Wrapf(
+			&HTTPError{
+				StatusCode: http.StatusRequestEntityTooLarge,
+				Body:       strings.NewReader("request body is too large"),
+			},
+			"TransformRequestBody: request body exceeds %d bytes",
+			maxSize,
+		)
+	}
+	if len(body) == 0 {
+		r.Body = http.NoBody
+		return nil
+	}
+
+	out, err := transformer.Transform(r.Context(), r.Header.Get("Content-Type"), body)
+	if err != nil {
+		if errors.Is(err, manipulation.ErrExecutionTimeout) ||
+			errors.Is(err, context.Canceled) ||
+			errors.Is(err, context.DeadlineExceeded) {
+			return errors.Wrap(err, "TransformRequestBody: cannot transform request body")
+		}
+		return errors.Wrapf(
+			&HTTPError{
+				StatusCode: http.StatusBadRequest,
+				Body:       strings.NewReader("invalid request body"),
+			},
+			"TransformRequestBody: invalid request body: %s",
+			err,
+		)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(out))
+	r.ContentLength = int64(len(out))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Length", strconv.Itoa(len(out)))
+	return nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/manipulation"
+)
+
+func TestTransformRequestBodyErrors(t *testing.T) {
+	timeout, err := manipulation.NewJavaScriptRemapper(manipulation.JavaScriptRemapperConfig{
+		Script:  `function remap(input) { for (;;) {} }`,
+		Timeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		ctx        context.Context
+		remapper   manipulation.Remapper
+		body       string
+		statusCode int
+	}{
+		{context.Background(), nil, `{"broken": `, http.StatusBadRequest},
+		{context.Background(), timeout, `{}`, 0},
+		{cancelled, timeout, `{}`, 0},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %s,", i, tt.body)
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)).WithContext(tt.ctx)
+		r.Header.Set("Content-Type", "application/json")
+		transformer := manipulation.NewRequestBodyTransformer(tt.remapper, nil)
+		err := TransformRequestBody(r, transformer, 0)
+		if err == nil {
+			t.Errorf("%s expected to return error", meta)
+			continue
+		}
+		httpErr, ok := errors.Cause(err).(*HTTPError)
+		if tt.statusCode == 0 {
+			if ok {
+				t.Errorf("%s expected not to be a client error, got HTTP %d", meta, httpErr.StatusCode)
+			}
+			continue
+		}
+		if !ok || httpErr.StatusCode != tt.statusCode {
+			t.Errorf("%s expected HTTP %d, got %v", meta, tt.statusCode, err)
+			continue
+		}
+		body, _ := io.ReadAll(httpErr.Body)
+		if string(body) != "invalid request body" {
+			t.Errorf("%s expected the generic message in the response body, got %q", meta, body)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/manipulation"
+)
+
+// FilenameTemplate renders the file name of the response and formats the Content-Disposition header.
+// The template is processed by the text/template engine with the manipulation.RequestData
+// as the template data e.g. "account-{{ .Params.id }}-{{ .Now.Format \"2006-01-02\" }}"
+type FilenameTemplate struct {
+	template  *template.Template
+	extension string
+}
+
+// NewFilenameTemplate parses the template, the extension is appended to the file name
+// unless the name already ends with it
+func NewFilenameTemplate(text, extension string) (*FilenameTemplate, error) {
+	tpl, err := template.New("filename").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "FilenameTemplate: cannot parse template")
+	}
+	return &FilenameTemplate{template: tpl, extension: extension}, nil
+}
+
+// Filename renders the file name, path separators are replaced with "_"
+func (f *FilenameTemplate) Filename(ctx context.Context) (string, error) {
+	name := new(strings.Builder)
+	if err := f.template.Execute(name, manipulation.RequestFromContext(ctx)); err != nil {
+		return "", errors.Wrap(err, "FilenameTemplate: cannot execute template")
+	}
+	filename := strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(name.String()))
+	if f.extension != "" && !strings.HasSuffix(strings.ToLower(filename), strings.ToLower(f.extension)) {
+		filename += f.extension
+	}
+	return filename, nil
+}
+
+// ContentDisposition returns the value of the Content-Disposition header for the rendered file name
+func (f *FilenameTemplate) ContentDisposition(ctx context.Context) (string, error) {
+	filename, err := f.Filename(ctx)
+	if err != nil {
+		return "", err
+	}
+	return AttachmentDisposition(filename), nil
+}
+
+// AttachmentDisposition formats the Content-Disposition header according to RFC 6266,
+// a file name which cannot be quoted as printable ASCII is passed as the UTF-8 "filename*" parameter (RFC 8187)
+// along with the ASCII "filename" fallback for the clients which do not support it
+func AttachmentDisposition(filename string) string {
+	fallback := new(strings.Builder)
+	quoted := true
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\' || r < ' ' || r >= utf8.RuneSelf || r == 0x7f:
+			fallback.WriteByte('_')
+			quoted = false
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	if quoted {
+		return fmt.Sprintf(`attachment; filename="%s"`, fallback)
+	}
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, encodeExtValue(filename))
+}
+
+// encodeExtValue percent-encodes the value except the attr-char characters of RFC 8187
+func encodeExtValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	encoded := new(strings.Builder)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isAttrChar(c) {
+			encoded.WriteByte(c)
+			continue
+		}
+		encoded.WriteByte('%')
+		encoded.WriteByte(hex[c>>4])
+		encoded.WriteByte(hex[c&0x0f])
+	}
+	return encoded.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
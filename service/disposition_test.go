@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/velmie/alternea/manipulation"
+	"github.com/velmie/alternea/route"
+)
+
+func TestAttachmentDisposition(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected string
+	}{
+		{"report.csv", `attachment; filename="report.csv"`},
+		{"annual report (2023).pdf", `attachment; filename="annual report (2023).pdf"`},
+		{`say "hi".txt`, `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`},
+		{`a\b.txt`, `attachment; filename="a_b.txt"; filename*=UTF-8''a%5Cb.txt`},
+		{"tab\t.txt", `attachment; filename="tab_.txt"; filename*=UTF-8''tab%09.txt`},
+		{"отчёт.csv", `attachment; filename="_____.csv"; filename*=UTF-8''%D0%BE%D1%82%D1%87%D1%91%D1%82.csv`},
+		{"naïve €.txt", `attachment; filename="na_ve _.txt"; filename*=UTF-8''na%C3%AFve%20%E2%82%AC.txt`},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %q,", i, tt.filename)
+		if actual := AttachmentDisposition(tt.filename); actual != tt.expected {
+			t.Errorf("%s expected %s, got %s", meta, tt.expected, actual)
+		}
+	}
+}
+
+func TestEncodeExtValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"abcXYZ019", "abcXYZ019"},
+		{"!#$&+-.^_`|~", "!#$&+-.^_`|~"},
+		{"a b", "a%20b"},
+		{"%*'()/;=?@", "%25%2A%27%28%29%2F%3B%3D%3F%40"},
+		{"€", "%E2%82%AC"},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %q,", i, tt.value)
+		if actual := encodeExtValue(tt.value); actual != tt.expected {
+			t.Errorf("%s expected %s, got %s", meta, tt.expected, actual)
+		}
+	}
+}
+
+func TestFilenameTemplate(t *testing.T) {
+	tests := []struct {
+		template  string
+		extension string
+		params    route.NamedPathParameters
+		target    string
+		expected  string
+	}{
+		{"account-{{ .Params.id }}", ".csv", route.NamedPathParameters{"id": "42"}, "/", "account-42.csv"},
+		{"account-{{ .Params.id }}", ".csv", route.NamedPathParameters{}, "/", "account-.csv"},
+		{"{{ .Query.name }}", ".csv", route.NamedPathParameters{}, "/?name=report.csv", "report.csv"},
+		{"{{ .Query.name }}", ".csv", route.NamedPathParameters{}, "/?name=REPORT.CSV", "REPORT.CSV"},
+		{"{{ .Query.name }}", ".csv", route.NamedPathParameters{}, "/?name=report.pdf", "report.pdf.csv"},
+		{"{{ .Query.name }}", "", route.NamedPathParameters{}, "/?name=report", "report"},
+		{" {{ .Query.name }} ", ".txt", route.NamedPathParameters{}, "/?name=../etc/passwd", ".._etc_passwd.txt"},
+		{`{{ .Now.Format "2006" }}`, "", route.NamedPathParameters{}, "/", "2023"},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %q with %v %s,", i, tt.template, tt.params, tt.target)
+		filename, err := NewFilenameTemplate(tt.template, tt.extension)
+		if err != nil {
+			t.Fatalf("%s unexpected error: %s", meta, err)
+		}
+		ctx := route.ContextWithParameters(context.Background(), tt.params)
+		ctx = manipulation.ContextWithRequest(ctx, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		manipulation.RequestFromContext(ctx).Now = time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+		actual, err := filename.Filename(ctx)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("%s expected %q, got %q", meta, tt.expected, actual)
+		}
+	}
+
+	if _, err := NewFilenameTemplate("{{ .Params.id ", ""); err == nil {
+		t.Errorf("expected to return error for the invalid template")
+	}
+	filename, err := NewFilenameTemplate("{{ .Unknown }}", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err = filename.ContentDisposition(context.Background()); err == nil {
+		t.Errorf("expected to return error for the unknown field")
+	}
+}
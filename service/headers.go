@@ -0,0 +1,177 @@
+package service
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/velmie/alternea/manipulation"
+)
+
+// Modes of handling the X-Forwarded-* headers
+const (
+	// ForwardedAppend passes the client X-Forwarded-* headers and appends the client address to X-Forwarded-For
+	ForwardedAppend = "append"
+	// ForwardedReplace replaces the client X-Forwarded-* headers with the values of the current request:
+	// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto
+	ForwardedReplace = "replace"
+	// ForwardedDrop removes the X-Forwarded-* headers, X-Forwarded-For is not added either
+	ForwardedDrop = "drop"
+)
+
+const forwardedHeaderPrefix = "X-Forwarded-"
+
+type RequestHeaderRulesConfig struct {
+	// Allow lists the client headers which are passed to the backend, all headers are passed if empty,
+	// a name ending with "*" matches all headers with the given prefix, names are case-insensitive
+	Allow []string
+	// Deny lists the client headers which are removed, the same patterns as in Allow are supported
+	Deny []string
+	// Set maps header names to the templates of their values, the headers override the client ones
+	Set map[string]string
+	// Append maps header names to the templates of the values added to the existing ones
+	Append map[string]string
+	// Forwarded is the mode of handling the X-Forwarded-* headers, ForwardedAppend by default
+	Forwarded string
+}
+
+// RequestHeaderRules rewrite the headers of the request sent to the backend.
+// Values to set or append are processed by the text/template engine with the manipulation.RequestData
+// of the client request as the template data and the "env" function which returns the environment variable,
+// e.g. "Bearer {{ env "SERVICE_TOKEN" }}" or "{{ .Params.id }}"
+type RequestHeaderRules struct {
+	allow     []string
+	deny      []string
+	set       map[string]*template.Template
+	append    map[string]*template.Template
+	forwarded string
+}
+
+func NewRequestHeaderRules(cfg RequestHeaderRulesConfig) (*RequestHeaderRules, error) {
+	rules := &RequestHeaderRules{
+		allow:     lowerAll(cfg.Allow),
+		deny:      lowerAll(cfg.Deny),
+		forwarded: cfg.Forwarded,
+	}
+	switch rules.forwarded {
+	case "":
+		rules.forwarded = ForwardedAppend
+	case ForwardedAppend, ForwardedReplace, ForwardedDrop:
+	default:
+		return nil, errors.Errorf(
+			"RequestHeaderRules: unknown forwarded headers mode '%s', want one of: %s, %s, %s",
+			cfg.Forwarded,
+			ForwardedAppend,
+			ForwardedReplace,
+			ForwardedDrop,
+		)
+	}
+	var err error
+	if rules.set, err = parseHeaderTemplates(cfg.Set); err != nil {
+		return nil, errors.Wrap(err, "RequestHeaderRules: cannot parse set")
+	}
+	if rules.append, err = parseHeaderTemplates(cfg.Append); err != nil {
+		return nil, errors.Wrap(err, "RequestHeaderRules: cannot parse append")
+	}
+	return rules, nil
+}
+
+// Apply rewrites the request headers: client headers which are not allowed or denied are removed,
+// the X-Forwarded-* headers are processed, then the headers are set and appended
+func (h *RequestHeaderRules) Apply(r *http.Request) error {
+	for name := range r.Header {
+		lowerName := strings.ToLower(name)
+		if matchHeader(h.deny, lowerName) || (len(h.allow) > 0 && !matchHeader(h.allow, lowerName)) {
+			r.Header.Del(name)
+		}
+	}
+
+	switch h.forwarded {
+	case ForwardedReplace:
+		deleteForwardedHeaders(r.Header)
+		r.Header.Set("X-Forwarded-Host", r.Host)
+		proto := "http"
+		if r.TLS != nil {
+			proto = "https"
+		}
+		r.Header.Set("X-Forwarded-Proto", proto)
+		// X-Forwarded-For is added by the reverse proxy
+	case ForwardedDrop:
+		deleteForwardedHeaders(r.Header)
+		// nil value prevents the reverse proxy from adding X-Forwarded-For
+		r.Header["X-Forwarded-For"] = nil
+	}
+
+	data := manipulation.RequestFromContext(r.Context())
+	for name, tpl := range h.set {
+		value, err := executeHeaderTemplate(tpl, data)
+		if err != nil {
+			return errors.Wrapf(err, "RequestHeaderRules: cannot set header '%s'", name)
+		}
+		r.Header.Set(name, value)
+	}
+	for name, tpl := range h.append {
+		value, err := executeHeaderTemplate(tpl, data)
+		if err != nil {
+			return errors.Wrapf(err, "RequestHeaderRules: cannot append header '%s'", name)
+		}
+		r.Header.Add(name, value)
+	}
+	return nil
+}
+
+func parseHeaderTemplates(values map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(values))
+	for name, value := range values {
+		tpl, err := template.New(name).
+			Option("missingkey=zero").
+			Funcs(template.FuncMap{"env": os.Getenv}).
+			Parse(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "header '%s'", name)
+		}
+		templates[name] = tpl
+	}
+	return templates, nil
+}
+
+func executeHeaderTemplate(tpl *template.Template, data *manipulation.RequestData) (string, error) {
+	value := new(strings.Builder)
+	if err := tpl.Execute(value, data); err != nil {
+		return "", err
+	}
+	return value.String(), nil
+}
+
+// matchHeader reports whether the lower case header name matches one of the patterns
+func matchHeader(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteForwardedHeaders(header http.Header) {
+	for name := range header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), forwardedHeaderPrefix) {
+			header.Del(name)
+		}
+	}
+}
+
+func lowerAll(values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = strings.ToLower(value)
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/velmie/alternea/manipulation"
+	"github.com/velmie/alternea/route"
+)
+
+func TestRequestHeaderRules(t *testing.T) {
+	t.Setenv("ALTERNEA_TEST_TOKEN", "secret")
+
+	tests := []struct {
+		cfg      RequestHeaderRulesConfig
+		header   http.Header
+		expected http.Header
+	}{
+		{
+			cfg:      RequestHeaderRulesConfig{},
+			header:   http.Header{"Accept": {"text/csv"}, "X-Forwarded-For": {"10.0.0.1"}},
+			expected: http.Header{"Accept": {"text/csv"}, "X-Forwarded-For": {"10.0.0.1"}},
+		},
+		{
+			cfg: RequestHeaderRulesConfig{Allow: []string{"accept", "X-Trace-*"}},
+			header: http.Header{
+				"Accept":       {"text/csv"},
+				"Cookie":       {"session=1"},
+				"X-Trace-Id":   {"abc"},
+				"X-Trace-Span": {"def"},
+				"X-Tracer":     {"ghi"},
+			},
+			expected: http.Header{"Accept": {"text/csv"}, "X-Trace-Id": {"abc"}, "X-Trace-Span": {"def"}},
+		},
+		{
+			cfg:      RequestHeaderRulesConfig{Deny: []string{"cookie", "x-internal-*"}},
+			header:   http.Header{"Accept": {"text/csv"}, "Cookie": {"session=1"}, "X-Internal-User": {"root"}},
+			expected: http.Header{"Accept": {"text/csv"}},
+		},
+		{
+			cfg:      RequestHeaderRulesConfig{Allow: []string{"x-*"}, Deny: []string{"x-internal-*"}},
+			header:   http.Header{"Accept": {"text/csv"}, "X-Internal-User": {"root"}, "X-Tenant": {"acme"}},
+			expected: http.Header{"X-Tenant": {"acme"}},
+		},
+		{
+			cfg: RequestHeaderRulesConfig{
+				Set: map[string]string{
+					"Authorization": `Bearer {{ env "ALTERNEA_TEST_TOKEN" }}`,
+					"X-Account":     "{{ .Params.id }}",
+					"X-Tenant":      `{{ index .Headers "X-Tenant" }}-eu`,
+					"X-Missing":     "{{ .Params.missing }}",
+				},
+			},
+			header: http.Header{"Authorization": {"Basic dXNlcg=="}, "X-Tenant": {"acme"}},
+			expected: http.Header{
+				"Authorization": {"Bearer secret"},
+				"X-Account":     {"42"},
+				"X-Tenant":      {"acme-eu"},
+				"X-Missing":     {""},
+			},
+		},
+		{
+			cfg: RequestHeaderRulesConfig{
+				Deny:   []string{"x-tenant"},
+				Append: map[string]string{"Via": "alternea", "X-Tenant": `{{ index .Headers "X-Tenant" }}`},
+			},
+			header:   http.Header{"Via": {"1.1 proxy"}, "X-Tenant": {"acme"}},
+			expected: http.Header{"Via": {"1.1 proxy", "alternea"}, "X-Tenant": {"acme"}},
+		},
+		{
+			cfg:      RequestHeaderRulesConfig{Forwarded: ForwardedAppend},
+			header:   http.Header{"X-Forwarded-For": {"10.0.0.1"}, "X-Forwarded-Host": {"client.example.com"}},
+			expected: http.Header{"X-Forwarded-For": {"10.0.0.1"}, "X-Forwarded-Host": {"client.example.com"}},
+		},
+		{
+			cfg: RequestHeaderRulesConfig{Forwarded: ForwardedReplace},
+			header: http.Header{
+				"X-Forwarded-For":    {"10.0.0.1"},
+				"X-Forwarded-Host":   {"client.example.com"},
+				"X-Forwarded-Proto":  {"https"},
+				"X-Forwarded-Prefix": {"/api"},
+			},
+			expected: http.Header{"X-Forwarded-Host": {"example.com"}, "X-Forwarded-Proto": {"http"}},
+		},
+		{
+			cfg: RequestHeaderRulesConfig{Forwarded: ForwardedDrop},
+			header: http.Header{
+				"Accept":            {"text/csv"},
+				"X-Forwarded-For":   {"10.0.0.1"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			expected: http.Header{"Accept": {"text/csv"}, "X-Forwarded-For": nil},
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: %+v, headers %v,", i, tt.cfg, tt.header)
+		rules, err := NewRequestHeaderRules(tt.cfg)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/accounts/42", nil)
+		r.Header = tt.header
+		ctx := route.ContextWithParameters(r.Context(), route.NamedPathParameters{"id": "42"})
+		r = r.WithContext(manipulation.ContextWithRequest(ctx, r))
+		if err = rules.Apply(r); err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if !reflect.DeepEqual(r.Header, tt.expected) {
+			t.Errorf("%s expected %v, got %v", meta, tt.expected, r.Header)
+		}
+	}
+}
+
+func TestRequestHeaderRulesForwardedByProxy(t *testing.T) {
+	var received http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer backend.Close()
+	target, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	tests := []struct {
+		mode     string
+		expected []string
+	}{
+		{ForwardedAppend, []string{"10.0.0.1, 192.0.2.1"}},
+		{ForwardedReplace, []string{"192.0.2.1"}},
+		{ForwardedDrop, nil},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: forwarded %q,", i, tt.mode)
+		rules, err := NewRequestHeaderRules(RequestHeaderRulesConfig{Forwarded: tt.mode})
+		if err != nil {
+			t.Fatalf("%s unexpected error: %s", meta, err)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-For", "10.0.0.1")
+		r = r.WithContext(manipulation.ContextWithRequest(context.Background(), r))
+		if err = rules.Apply(r); err != nil {
+			t.Fatalf("%s unexpected error: %s", meta, err)
+		}
+		received = nil
+		proxy.ServeHTTP(httptest.NewRecorder(), r)
+		if actual := received.Values("X-Forwarded-For"); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%s expected X-Forwarded-For %v, got %v", meta, tt.expected, actual)
+		}
+	}
+}
+
+func TestRequestHeaderRulesInvalidConfig(t *testing.T) {
+	configs := []RequestHeaderRulesConfig{
+		{Forwarded: "keep"},
+		{Set: map[string]string{"X-Account": "{{ .Params.id "}},
+		{Append: map[string]string{"X-Account": "{{ end }}"}},
+	}
+	for i, cfg := range configs {
+		if _, err := NewRequestHeaderRules(cfg); err == nil {
+			t.Errorf("test #%d: %+v, expected to return error", i, cfg)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FormatQueryParameter is the query string parameter which selects the output format explicitly
+const FormatQueryParameter = "format"
+
+// Variant describes one of the output formats of a resource
+type Variant struct {
+	Format    string // Format is the value of the format query parameter which selects the variant e.g. "csv"
+	MediaType string // MediaType is matched against the Accept header e.g. "text/csv"
+}
+
+// Negotiate returns the index of the variant selected by the format, if it is not empty,
+// or by the Accept header. The variant with the highest quality value wins, the order of variants
+// breaks ties, so the first variant is used if the Accept header is missing.
+// If no variant is acceptable the error is 406 Not Acceptable
+func Negotiate(variants []Variant, format, accept string) (int, error) {
+	if format != "" {
+		for i, variant := range variants {
+			if strings.EqualFold(variant.Format, format) {
+				return i, nil
+			}
+		}
+		return 0, notAcceptable(variants, "format '"+format+"' is not supported")
+	}
+	if strings.TrimSpace(accept) == "" {
+		return 0, nil
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQuality := -1, 0.0
+	for i, variant := range variants {
+		if quality := acceptQuality(ranges, variant.MediaType); quality > bestQuality {
+			best, bestQuality = i, quality
+		}
+	}
+	if best < 0 {
+		return 0, notAcceptable(variants, "none of the accepted media types is supported")
+	}
+	return best, nil
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range which matches the media type (RFC 7231 5.3.2),
+// the variant without the media type is matched by "*/*" only
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	if mediaType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(mediaType); err != nil {
+			return 0
+		}
+	}
+	typ, _, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, 0
+	for _, r := range ranges {
+		s := 0
+		switch r.mediaType {
+		case mediaType:
+			s = 3
+		case typ + "/*":
+			s = 2
+		case "*/*":
+			s = 1
+		}
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality
+}
+
+func notAcceptable(variants []Variant, reason string) error {
+	available := make([]string, len(variants))
+	for i, variant := range variants {
+		available[i] = variant.Format
+		if variant.MediaType != "" {
+			available[i] += " (" + variant.MediaType + ")"
+		}
+	}
+	message := reason + ", available: " + strings.Join(available, ", ")
+	return errors.Wrap(
+		&HTTPError{StatusCode: http.StatusNotAcceptable, Body: strings.NewReader(message)},
+		"Negotiate: "+message,
+	)
+}
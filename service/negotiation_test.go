@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestNegotiate(t *testing.T) {
+	variants := []Variant{
+		{Format: "json", MediaType: "application/json"},
+		{Format: "csv", MediaType: "text/csv"},
+		{Format: "html", MediaType: "text/html"},
+		{Format: "raw"},
+	}
+	tests := []struct {
+		format   string
+		accept   string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "   ", 0},
+		{"csv", "application/json", 1},
+		{"CSV", "", 1},
+		{"raw", "", 3},
+		{"", "text/csv", 1},
+		{"", "text/csv; charset=utf-8", 1},
+		{"", "text/html, text/csv", 1},
+		{"", "text/html;q=0.5, text/csv", 1},
+		{"", "text/*;q=0.8, text/html", 2},
+		{"", "text/*, text/html;q=0.1", 1},
+		{"", "text/*;q=0.1, */*;q=0.5", 0},
+		{"", "*/*", 0},
+		{"", "*/*;q=0.5, text/csv;q=0.4", 0},
+		{"", "application/json;q=0, */*;q=0.1", 1},
+		{"", "image/png, */*;q=0.1", 0},
+		{"", "application/json;q=0, text/*;q=0, */*", 3},
+		{"", "text/csv;q=invalid, text/html", 2},
+		{"", "text/csv;q=2, text/html;q=0.5", 2},
+		{"", "invalid, text/csv", 1},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: format %q, Accept %q,", i, tt.format, tt.accept)
+		actual, err := Negotiate(variants, tt.format, tt.accept)
+		if err != nil {
+			t.Errorf("%s unexpected error: %s", meta, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("%s expected variant %d, got %d", meta, tt.expected, actual)
+		}
+	}
+}
+
+func TestNegotiateNotAcceptable(t *testing.T) {
+	tests := []struct {
+		variants []Variant
+		format   string
+		accept   string
+	}{
+		{[]Variant{{Format: "json", MediaType: "application/json"}}, "xml", ""},
+		{[]Variant{{Format: "json", MediaType: "application/json"}}, "", "text/csv"},
+		{[]Variant{{Format: "json", MediaType: "application/json"}}, "", "application/json;q=0"},
+		{[]Variant{{Format: "json", MediaType: "application/json"}}, "", "invalid"},
+		{[]Variant{{Format: "raw"}}, "", "text/*"},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf("test #%d: format %q, Accept %q,", i, tt.format, tt.accept)
+		_, err := Negotiate(tt.variants, tt.format, tt.accept)
+		httpErr, ok := errors.Cause(err).(*HTTPError)
+		if !ok || httpErr.StatusCode != http.StatusNotAcceptable {
+			t.Errorf("%s expected HTTP %d, got %v", meta, http.StatusNotAcceptable, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/velmie/alternea/httpbackend"
@@ -17,14 +18,37 @@ type TransformerHandler struct {
 	transformer            manipulation.DataTransformer
 	requestIterator        RequestIterator
 	backend                httpbackend.RequestHandler
+	validator              manipulation.Validator
 	errorHandler           func(err error) (proceed bool)
 	flushInterval          time.Duration
 	successHTTPStatusCodes []int
+	passHeaders            []string
+	preferBackendHeaders   bool
 }
 
 type TransformerHandlerConfig struct {
 	ErrorHandler           func(err error) (proceed bool)
 	SuccessHTTPStatusCodes []int
+	// Validator optionally validates every page before it is passed to the transformer,
+	// the request fails with 502 Bad Gateway if the page is invalid
+	Validator manipulation.Validator
+	// PassHeaders lists the headers of the backend response which are copied to the response
+	// before the first page is passed to the transformer, names are case-insensitive,
+	// a name ending with "*" matches all headers with the given prefix,
+	// the headers describing the body of the backend response e.g. Content-Length are never copied
+	PassHeaders []string
+	// PreferBackendHeaders set to true to override the headers which are already set in the response
+	// e.g. by the configuration, otherwise such headers are kept
+	PreferBackendHeaders bool
+}
+
+// notPassedHeaders are not copied from the backend response since the response body is transformed
+var notPassedHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Trailer":           true,
 }
 
 func NewTransformerHandler(
@@ -41,6 +65,9 @@ func NewTransformerHandler(
 	if len(config) > 0 {
 		handler.errorHandler = config[0].ErrorHandler
 		handler.successHTTPStatusCodes = config[0].SuccessHTTPStatusCodes
+		handler.validator = config[0].Validator
+		handler.passHeaders = lowerAll(config[0].PassHeaders)
+		handler.preferBackendHeaders = config[0].PreferBackendHeaders
 	}
 	if len(handler.successHTTPStatusCodes) == 0 {
 		handler.successHTTPStatusCodes = []int{http.StatusOK}
@@ -48,40 +75,76 @@ func NewTransformerHandler(
 	return handler
 }
 
+// Handle requests the backend and writes the transformed data to the writer,
+// if the writer is a http.ResponseWriter then the backend headers are passed according to the configuration
 func (h *TransformerHandler) Handle(
 	ctx context.Context,
 	w io.Writer,
 	request *http.Request,
 ) error {
-	transformerChanel := make(chan []byte)
-
-	// net/http/httputil/reverseproxy.go
-	if h.flushInterval != 0 {
-		if wf, ok := w.(writeFlusher); ok {
-			mlw := &maxLatencyWriter{
-				dst:     wf,
-				latency: h.flushInterval,
+	var passed []string
+	err := h.handle(ctx, w, request, &passed)
+	if err != nil {
+		// the response is going to describe the error, so the backend headers are not relevant
+		if header := responseHeader(w); header != nil {
+			for _, name := range passed {
+				header.Del(name)
 			}
-			defer mlw.stop()
-
-			// set up initial timer so headers get flushed even if body writes are delayed
-			mlw.flushPending = true
-			mlw.t = time.AfterFunc(h.flushInterval, mlw.delayedFlush)
-
-			w = mlw
 		}
 	}
+	return err
+}
+
+func (h *TransformerHandler) handle(
+	ctx context.Context,
+	w io.Writer,
+	request *http.Request,
+	passed *[]string,
+) error {
+	header := responseHeader(w)
+	transformerChanel := make(chan []byte)
 
 	errs, ctx := errgroup.WithContext(ctx)
-	errs.Go(func() error {
-		err := h.transformer.Transform(ctx, transformerChanel, w)
-		return err
-	})
+	var mlw *maxLatencyWriter
+	defer func() {
+		if mlw != nil {
+			mlw.stop()
+		}
+	}()
+	// start runs the transformer and the flush timer once the headers of the first response are passed,
+	// since either of them may send the response headers
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		// net/http/httputil/reverseproxy.go
+		if h.flushInterval != 0 {
+			if wf, ok := w.(writeFlusher); ok {
+				mlw = &maxLatencyWriter{
+					dst:     wf,
+					latency: h.flushInterval,
+				}
+
+				// set up initial timer so headers get flushed even if body writes are delayed
+				mlw.flushPending = true
+				mlw.t = time.AfterFunc(h.flushInterval, mlw.delayedFlush)
+
+				w = mlw
+			}
+		}
+		errs.Go(func() error {
+			err := h.transformer.Transform(ctx, transformerChanel, w)
+			return err
+		})
+	}
 
 	var (
-		response *httpbackend.Response
-		data     []byte
-		err      error
+		response      *httpbackend.Response
+		data          []byte
+		err           error
+		headersPassed bool
 	)
 	for {
 		request, err = h.requestIterator.Next(request, data)
@@ -89,6 +152,7 @@ func (h *TransformerHandler) Handle(
 			return errors.Wrap(err, "TransformerHandler: cannot get request from iterator")
 		}
 		if request == nil {
+			start()
 			close(transformerChanel)
 			break
 		}
@@ -124,12 +188,60 @@ func (h *TransformerHandler) Handle(
 			close(transformerChanel)
 			return errors.Wrap(err, "TransformerHandler: cannot read response body")
 		}
-		transformerChanel <- data
+		if h.validator != nil {
+			if err = h.validator.Validate(ctx, data); err != nil {
+				close(transformerChanel)
+				return errors.Wrapf(
+					&HTTPError{
+						StatusCode: http.StatusBadGateway,
+						Body:       strings.NewReader("invalid backend response: " + err.Error()),
+					},
+					"TransformerHandler: invalid backend response: %s",
+					err,
+				)
+			}
+		}
+		if header != nil && !headersPassed {
+			// only the headers of the first response are passed since they are written along with the first page
+			*passed = h.passResponseHeaders(response.Header, header)
+			headersPassed = true
+		}
+		start()
+		select {
+		case transformerChanel <- data:
+		case <-ctx.Done():
+			// the transformer has stopped, its error is returned by errs.Wait
+			return errs.Wait()
+		}
 	}
 
 	return errs.Wait()
 }
 
+// passResponseHeaders copies the configured backend headers and returns their names
+func (h *TransformerHandler) passResponseHeaders(from, to http.Header) []string {
+	var passed []string
+	for name, values := range from {
+		if notPassedHeaders[name] || !matchHeader(h.passHeaders, strings.ToLower(name)) {
+			continue
+		}
+		if _, ok := to[name]; ok && !h.preferBackendHeaders {
+			continue
+		}
+		to[name] = append([]string(nil), values...)
+		passed = append(passed, name)
+	}
+	return passed
+}
+
+// responseHeader returns the header map of the writer if it is a http.ResponseWriter
+func responseHeader(w io.Writer) http.Header {
+	if hw, ok := w.(interface{ Header() http.Header }); ok {
+		return hw.Header()
+	}
+	return nil
+}
+
 // SetFlushInterval specifies the flush interval
 // to flush to the writer while copying the
 // transformed data.
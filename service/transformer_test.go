@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/velmie/alternea/httpbackend"
+)
+
+type backendFunc func(request *http.Request) (*httpbackend.Response, error)
+
+func (f backendFunc) HandleRequest(request *http.Request) (*httpbackend.Response, error) {
+	return f(request)
+}
+
+// eagerTransformer writes the opening bracket before it receives the first page
+type eagerTransformer struct{}
+
+func (eagerTransformer) MediaType() string {
+	return "application/json"
+}
+
+func (eagerTransformer) FileExtension() string {
+	return ".json"
+}
+
+func (eagerTransformer) Transform(_ context.Context, pages <-chan []byte, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for page := range pages {
+		if _, err := w.Write(page); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+func TestTransformerHandlerPassesHeadersBeforeFlush(t *testing.T) {
+	backend := backendFunc(func(request *http.Request) (*httpbackend.Response, error) {
+		time.Sleep(50 * time.Millisecond)
+		return &httpbackend.Response{
+			Request:    request,
+			Body:       strings.NewReader(`{"id":1}`),
+			Header:     http.Header{"X-Request-Id": []string{"42"}},
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+	handler := NewTransformerHandler(eagerTransformer{}, NewDirectRequestIterator(), backend, &TransformerHandlerConfig{
+		PassHeaders: []string{"X-Request-Id"},
+	})
+	handler.SetFlushInterval(time.Millisecond)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := handler.Handle(r.Context(), w, r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	response := w.Result()
+	if response.Header.Get("X-Request-Id") != "42" {
+		t.Errorf("expected the backend header to be sent, got headers %v", response.Header)
+	}
+	if w.Body.String() != `[{"id":1}]` {
+		t.Errorf("expected %s, got %s", `[{"id":1}]`, w.Body.String())
+	}
+}